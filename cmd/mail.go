@@ -0,0 +1,20 @@
+package main
+
+import (
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/mail"
+
+	"go.uber.org/zap"
+)
+
+// mailTransport собирает транспорт из MAIL_TRANSPORT; nil — письма не
+// отправляются и не ставятся в очередь.
+func mailTransport(cfg config.Mail, logger *zap.Logger) mail.Transport {
+	switch cfg.Transport {
+	case "log":
+		return mail.NewLogTransport(logger)
+	case "smtp":
+		return mail.NewSMTPTransport(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPTimeout)
+	}
+	return nil
+}
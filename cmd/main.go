@@ -2,77 +2,222 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
+	"zadanie-6105/internal/config"
 	"zadanie-6105/internal/delivery"
+	"zadanie-6105/internal/health"
+	"zadanie-6105/internal/mail"
+	"zadanie-6105/internal/metrics"
+	"zadanie-6105/internal/outbox"
 	"zadanie-6105/internal/repositories"
 	"zadanie-6105/internal/services"
+	"zadanie-6105/internal/tracing"
+	"zadanie-6105/internal/workers"
+	"zadanie-6105/migrations"
 
-	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
 func main() {
-	logger := zap.Must(zap.NewProduction())
-	if err := godotenv.Load("cmd/main.env"); err != nil {
-		log.Fatal("No .env file found")
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal(err)
+	}
+	if cfg.PrintConfig {
+		cfg.Print(os.Stdout)
+		if err := cfg.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	zapCfg := zap.NewProductionConfig()
+	zapCfg.Level, _ = zap.ParseAtomicLevel(cfg.Log.Level)
+	logger := zap.Must(zapCfg.Build())
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Fatal("tracing setup failed", zap.Error(err))
 	}
-	SERVER_ADDRESS := os.Getenv("SERVER_ADDRESS")
-	POSTGRES_CONN := os.Getenv("POSTGRES_CONN")
 
-	db, err := pgxpool.New(context.Background(), POSTGRES_CONN)
+	// Пока ждем базу, сервис можно остановить сигналом
+	startCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	if len(args) > 0 {
+		if args[0] != "migrate" {
+			log.Fatalf("неизвестная команда %q", args[0])
+		}
+		err := withMigrator(startCtx, cfg.Postgres, logger, func(m *migrations.Migrator) error {
+			return runMigrate(context.Background(), m, args[1:])
+		})
+		stop()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if cfg.Features.MigrateOnStart {
+		err := withMigrator(startCtx, cfg.Postgres, logger, func(m *migrations.Migrator) error {
+			return m.Up(context.Background())
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	db, err := repositories.NewPool(startCtx, cfg.Postgres, logger)
+	stop()
 	if err != nil {
-		fmt.Println("error wih db", err)
+		logger.Fatal("database is unavailable", zap.Error(err))
 	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checker := health.NewChecker(db, migrator, migrations.Latest())
+	healthHandler := delivery.NewHealthHandler(checker, logger)
 
 	userRepo := repositories.NewUserRepo(db)
+	auditRepo := repositories.NewAuditRepo(db)
 	tenderRepo := repositories.NewTenderRepo(db)
-	tenderService := services.NewTenderService(tenderRepo, userRepo)
+	authz := services.NewAuthorizer(userRepo, tenderRepo)
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepo(db), authz, nil)
+	webhook := delivery.NewWebhookHandler(webhookService, logger)
+	txManager := repositories.NewTxManager(db)
+	outboxRepo := repositories.NewOutboxRepo(db)
+	events := services.NewOutboxPublisher(outboxRepo)
+	tenderService := services.NewTenderService(tenderRepo, userRepo, auditRepo, txManager, events, authz)
 	tender := delivery.NewTenderHandler(tenderService, logger)
 	bidRepo := repositories.NewBidRepo(db)
-	bidService := services.NewBidService(bidRepo, userRepo, tenderRepo)
+	keyRepo := repositories.NewKeyRepo(db)
+	bidService := services.NewBidService(bidRepo, userRepo, tenderRepo, auditRepo, keyRepo, txManager, events, authz)
 	bid := delivery.NewBidHandler(bidService, logger)
+	signingKey, _ := cfg.Auth.SigningKey() // ключ уже проверен в config.Validate
+	auditService := services.NewAuditService(auditRepo, userRepo, tenderRepo, bidRepo, authz, signingKey)
+	audit := delivery.NewAuditHandler(auditService, logger)
+	key := delivery.NewKeyHandler(services.NewKeyService(keyRepo, userRepo), logger)
+	organization := delivery.NewOrganizationHandler(services.NewOrganizationService(repositories.NewOrganizationRepo(db), userRepo, txManager, authz), logger)
+	employee := delivery.NewEmployeeHandler(services.NewEmployeeService(userRepo), logger)
 
-	r := mux.NewRouter().PathPrefix("/api").Subrouter()
-	r.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	r.HandleFunc("/tenders", tender.GetTenderList).Methods("GET")
-	r.HandleFunc("/tenders/new", tender.CreateTender).Methods("POST")
-	r.HandleFunc("/tenders/my", tender.GetTenderByUser).Methods("GET")
-	r.HandleFunc("/tenders/{tenderId}/status", tender.GetTenderStatus).Methods("GET")
-	r.HandleFunc("/tenders/{tenderId}/status", tender.ChangeTenderStatus).Methods("PUT")
-	r.HandleFunc("/tenders/{tenderId}/edit", tender.EditTender).Methods("PATCH")
-	r.HandleFunc("/bids/new", bid.CreateBid).Methods("POST")
-	r.HandleFunc("/bids/my", bid.GetUserBids).Methods("GET")
-	r.HandleFunc("/bids/{tenderId}/my", bid.GetBidsForTender).Methods("GET")
-	r.HandleFunc("/bids/{bidId}/status", bid.GetBidStatus).Methods("GET")
-	r.HandleFunc("/bids/{bidId}/status", bid.ChangeBidStatus).Methods("PUT")
-	r.HandleFunc("/bids/{bidId}/submit_decision", bid.SubmitBid).Methods("PUT")
-	r.HandleFunc("/bids/{bidId}/edit", bid.EditBid).Methods("PATCH")
+	bus := outbox.NewBus()
+	sinks, closeSinks, err := outboxSinks(cfg.Outbox, logger, webhookService, bus)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeSinks()
+	notificationRepo := repositories.NewNotificationRepo(db)
+	transport := mailTransport(cfg.Mail, logger)
+	renderer, err := mail.NewRenderer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mailService := services.NewMailService(repositories.NewMailRepo(db), notificationRepo, userRepo, tenderRepo,
+		txManager, renderer, transport, cfg.Mail.From)
+	mailHandler := delivery.NewMailHandler(mailService, logger)
+	var mailer services.Mailer
+	if transport != nil {
+		mailer = mailService
+	}
+	searchRepo := repositories.NewSearchRepo(db)
+	search := delivery.NewSearchHandler(services.NewSearchService(searchRepo, userRepo, tenderRepo, authz), logger)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, tenderRepo, searchRepo, txManager, mailer)
+	notification := delivery.NewNotificationHandler(notificationService, logger)
+	// уведомления в приложении не зависят от OUTBOX_SINKS
+	sinks = append(sinks, outbox.NewEventsSink("notifications", notificationService))
+	dispatcher := outbox.NewDispatcher(outboxRepo, sinks, logger)
+	hub := outbox.NewHub(outboxRepo, logger)
+	stream := delivery.NewStreamHandler(services.NewStreamService(outboxRepo, tenderRepo, bidRepo, userRepo, authz, hub), logger)
+
+	prometheus.MustRegister(metrics.NewPoolCollector(db))
+
+	// фоновые задачи работают со всеми строками, RLS для них снят явно
+	workerCtx, stopWorkers := context.WithCancel(repositories.System(context.Background()))
+	defer stopWorkers()
+	sweeper := checker.RegisterWorker("sealed_bid_sweeper", cfg.Workers.SealedSweepInterval)
+	go workers.Run(workerCtx, "sealed_bid_sweeper", cfg.Workers.SealedSweepInterval, sweeper, logger,
+		func(ctx context.Context) error {
+			n, err := bidService.DisqualifyUnrevealed(ctx)
+			if n > 0 {
+				logger.Info("unrevealed bids disqualified", zap.Int("count", n))
+			}
+			return err
+		})
+	webhookWorker := checker.RegisterWorker("webhook_sender", cfg.Workers.WebhookInterval)
+	go workers.Run(workerCtx, "webhook_sender", cfg.Workers.WebhookInterval, webhookWorker, logger,
+		func(ctx context.Context) error {
+			_, err := webhookService.DeliverPending(ctx)
+			return err
+		})
+	go hub.Run(workerCtx)
+	outboxWorker := checker.RegisterWorker("outbox_dispatcher", cfg.Workers.OutboxInterval)
+	go workers.Run(workerCtx, "outbox_dispatcher", cfg.Workers.OutboxInterval, outboxWorker, logger,
+		func(ctx context.Context) error {
+			_, err := dispatcher.Dispatch(ctx)
+			return err
+		})
+
+	deadlineWorker := checker.RegisterWorker("deadline_reminder", cfg.Workers.DeadlineInterval)
+	go workers.Run(workerCtx, "deadline_reminder", cfg.Workers.DeadlineInterval, deadlineWorker, logger,
+		func(ctx context.Context) error {
+			_, err := tenderService.RemindDeadlines(ctx, cfg.Mail.DeadlineReminder)
+			return err
+		})
+	if transport != nil {
+		mailWorker := checker.RegisterWorker("mail_sender", cfg.Workers.MailInterval)
+		go workers.Run(workerCtx, "mail_sender", cfg.Workers.MailInterval, mailWorker, logger,
+			func(ctx context.Context) error {
+				_, err := mailService.SendPending(ctx)
+				return err
+			})
+		digestWorker := checker.RegisterWorker("mail_digest", cfg.Workers.DigestInterval)
+		go workers.Run(workerCtx, "mail_digest", cfg.Workers.DigestInterval, digestWorker, logger,
+			func(ctx context.Context) error {
+				_, err := mailService.SendDigests(ctx)
+				return err
+			})
+	}
+
+	router := delivery.NewRouter(delivery.Handlers{
+		Health:       healthHandler,
+		Tender:       tender,
+		Bid:          bid,
+		Search:       search,
+		Organization: organization,
+		Employee:     employee,
+		Key:          key,
+		Webhook:      webhook,
+		Stream:       stream,
+		Notification: notification,
+		Mail:         mailHandler,
+		Audit:        audit,
+	}, authz, logger)
 
 	srv := &http.Server{
-		Addr:    SERVER_ADDRESS,
-		Handler: r,
+		Addr:         cfg.Server.Address,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("listen: %s\\n", err)
 		}
 	}()
 
 	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 5 seconds.
+	// a timeout of SERVER_SHUTDOWN_TIMEOUT.
 	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
@@ -80,16 +225,21 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutdown Server ...")
+	checker.ShuttingDown()
+	stopWorkers()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server Shutdown:", err)
 	}
-	// catching ctx.Done(). timeout of 5 seconds.
+	// catching ctx.Done(). timeout of SERVER_SHUTDOWN_TIMEOUT.
 	select {
 	case <-ctx.Done():
-		log.Println("timeout of 5 seconds.")
+		log.Println("shutdown timeout", cfg.Server.ShutdownTimeout)
+	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Println("tracing shutdown:", err)
 	}
 	log.Println("Server exiting")
 }
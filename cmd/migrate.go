@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/migrations"
+
+	"go.uber.org/zap"
+)
+
+// withMigrator выполняет fn на отдельном пуле без переменных RLS: схема
+// может быть еще пустой или старой.
+func withMigrator(ctx context.Context, cfg config.Postgres, logger *zap.Logger, fn func(m *migrations.Migrator) error) error {
+	db, err := repositories.NewMigrationPool(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+const migrateUsage = "использование: migrate status|up|down|to VERSION|seed"
+
+func runMigrate(ctx context.Context, m *migrations.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(ctx, m)
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("неверная версия %q: %w", args[1], err)
+		}
+		return m.To(ctx, version)
+	case "seed":
+		return m.Seed(ctx)
+	}
+	return errors.New(migrateUsage)
+}
+
+func printMigrationStatus(ctx context.Context, m *migrations.Migrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED_AT")
+	for _, st := range status {
+		applied := "-"
+		if st.AppliedAt != nil {
+			applied = st.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/outbox"
+	"zadanie-6105/internal/services"
+
+	"go.uber.org/zap"
+)
+
+// outboxSinks собирает получателей событий из OUTBOX_SINKS. close закрывает
+// внешние соединения при остановке.
+func outboxSinks(cfg config.Outbox, logger *zap.Logger, webhooks services.Events, bus *outbox.Bus) ([]outbox.Sink, func(), error) {
+	var (
+		sinks   []outbox.Sink
+		closers []func()
+	)
+	close := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	for _, name := range cfg.SinkList() {
+		switch name {
+		case "log":
+			sinks = append(sinks, outbox.NewLogSink(logger))
+		case "webhook":
+			sinks = append(sinks, outbox.NewEventsSink("webhook", webhooks))
+		case "bus":
+			sinks = append(sinks, bus)
+		case "nats":
+			sink, err := outbox.NewNATSSink(cfg.NATSURL, cfg.NATSSubject)
+			if err != nil {
+				close()
+				return nil, nil, err
+			}
+			sinks = append(sinks, sink)
+			closers = append(closers, sink.Close)
+		}
+	}
+	return sinks, close, nil
+}
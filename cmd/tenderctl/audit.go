@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+)
+
+// verifyChain проверяет цепочку журнала тендера и завершается с ошибкой,
+// если нашлось поврежденное звено.
+func verifyChain(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("нужно указать id тендера")
+	}
+	chain, err := a.audit.GetChain(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	report := services.VerifyChain(args[0], chain)
+	row := []string{report.TenderID, strconv.Itoa(report.Entries), strconv.FormatBool(report.Valid), report.Head, "", ""}
+	if report.BrokenAt != nil {
+		row[3] = ""
+		row[4] = strconv.FormatInt(report.BrokenAt.Seq, 10)
+		row[5] = report.BrokenAt.Reason
+	}
+	header := []string{"TENDER_ID", "ENTRIES", "VALID", "HEAD", "BROKEN_SEQ", "REASON"}
+	if err := a.out.print(report, header, [][]string{row}); err != nil {
+		return err
+	}
+	if !report.Valid {
+		return fmt.Errorf("цепочка повреждена на записи %d (id %d)", report.BrokenAt.Seq, report.BrokenAt.ID)
+	}
+	return nil
+}
+
+// chainHead выгружает подписанную голову цепочки для передачи аудиторам.
+func chainHead(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("нужно указать id тендера")
+	}
+	if a.key == nil {
+		return services.ErrSigningDisabled
+	}
+	chain, err := a.audit.GetChain(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	report := services.VerifyChain(args[0], chain)
+	if !report.Valid {
+		return fmt.Errorf("цепочка повреждена на записи %d: %s", report.BrokenAt.Seq, report.BrokenAt.Reason)
+	}
+	head := services.SignChainHead(a.key, report, time.Now().UTC())
+	header := []string{"TENDER_ID", "SEQ", "HASH", "SIGNED_AT", "PUBLIC_KEY", "SIGNATURE"}
+	row := []string{head.TenderID, strconv.FormatInt(head.Seq, 10), head.Hash, formatTime(head.SignedAt),
+		head.PublicKey, head.Signature}
+	return a.out.print(head, header, [][]string{row})
+}
+
+type version struct {
+	Version   int            `json:"version"`
+	Action    string         `json:"action"`
+	ActorID   string         `json:"actor_id,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+	Changes   map[string]any `json:"changes"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// printVersions выводит историю версий тендера или предложения из журнала
+// аудита: каждая запись — изменение с номером версии после него.
+func printVersions(ctx context.Context, a *app, entity entities.AuditEntity, id string) error {
+	entries, err := a.audit.GetByEntity(ctx, entity, id)
+	if err != nil {
+		return err
+	}
+	var (
+		res  []version
+		rows [][]string
+		cur  int
+	)
+	for _, e := range entries {
+		var d struct {
+			After map[string]any `json:"after"`
+		}
+		if err := json.Unmarshal(e.Diff, &d); err != nil {
+			return err
+		}
+		if v, ok := d.After["version"].(float64); ok {
+			cur = int(v)
+		}
+		res = append(res, version{cur, string(e.Action), e.ActorID, e.Reason, d.After, e.CreatedAt})
+		var fields []string
+		for k := range d.After {
+			if k != "version" {
+				fields = append(fields, k)
+			}
+		}
+		slices.Sort(fields)
+		rows = append(rows, []string{strconv.Itoa(cur), string(e.Action), e.ActorID,
+			strings.Join(fields, ","), e.Reason, formatTime(e.CreatedAt)})
+	}
+	if len(res) == 0 {
+		return errors.New("в журнале нет записей для " + id)
+	}
+	header := []string{"VERSION", "ACTION", "ACTOR_ID", "CHANGED", "REASON", "CREATED_AT"}
+	return a.out.print(res, header, rows)
+}
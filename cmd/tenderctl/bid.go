@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+var bidHeader = []string{"ID", "NAME", "STATUS", "AUTHOR_TYPE", "AUTHOR_ID", "VERSION", "CREATED_AT"}
+
+func bidRow(b entities.Bid) []string {
+	return []string{b.ID, b.Name, string(b.Status), b.AuthorType, b.AuthorID,
+		strconv.Itoa(int(b.Version)), formatTime(b.CreatedAt)}
+}
+
+func listBids(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("bid list", flag.ContinueOnError)
+	limit := fs.Int("limit", 50, "количество предложений")
+	offset := fs.Int("offset", 0, "смещение")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("нужно указать id тендера")
+	}
+	bids, err := a.bid.GetByTender(ctx, fs.Arg(0), operation.BidParams{Limit: *limit, Offset: *offset},
+		entities.Viewer{All: true})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(bids))
+	for _, b := range bids {
+		rows = append(rows, bidRow(b))
+	}
+	return a.out.print(bids, bidHeader, rows)
+}
+
+func getBid(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("нужно указать id предложения")
+	}
+	b, err := a.bid.GetByID(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	header := append(bidHeader, "TENDER_ID")
+	row := append(bidRow(b), b.TenderID)
+	return a.out.print(b, header, [][]string{row})
+}
+
+func bidVersions(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("нужно указать id предложения")
+	}
+	return printVersions(ctx, a, entities.AuditEntityBid, args[0])
+}
+
+func setBidStatus(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("bid set-status", flag.ContinueOnError)
+	reason := fs.String("reason", "", "причина изменения")
+	actor := fs.String("actor", "", "логин сотрудника, от имени которого вносится изменение")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 || *reason == "" {
+		return errors.New("нужно указать -reason, id предложения и статус")
+	}
+	var status entities.BidStatus
+	status.Scan(fs.Arg(1))
+	if status == "" {
+		return errors.New("неизвестный статус предложения: " + fs.Arg(1))
+	}
+	actorId, err := a.actorID(ctx, *actor)
+	if err != nil {
+		return err
+	}
+	before, err := a.bid.GetByID(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := a.bids.ForceBidStatus(ctx, status, fs.Arg(0), actorId, *reason)
+	if err != nil {
+		return err
+	}
+	a.logger.Info("bid status forced",
+		zap.String("bid_id", b.ID),
+		zap.String("from", string(before.Status)),
+		zap.String("to", string(status)),
+		zap.String("reason", *reason))
+	return a.out.print(b, bidHeader, [][]string{bidRow(b)})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+func createEmployee(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("employee create", flag.ContinueOnError)
+	username := fs.String("username", "", "логин")
+	firstName := fs.String("first-name", "", "имя")
+	lastName := fs.String("last-name", "", "фамилия")
+	email := fs.String("email", "", "адрес для писем")
+	locale := fs.String("locale", "ru", "язык писем: ru или en")
+	admin := fs.Bool("admin", false, "администратор платформы: может создавать организации")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("нужно указать -username")
+	}
+	if *locale != "ru" && *locale != "en" {
+		return errors.New("-locale: ожидается ru или en")
+	}
+	res, err := a.user.Create(ctx, entities.Employee{
+		Username:  *username,
+		FirstName: *firstName,
+		LastName:  *lastName,
+		Email:     *email,
+		Locale:    *locale,
+		IsAdmin:   *admin,
+	})
+	if err != nil {
+		return err
+	}
+	return a.out.print(res,
+		[]string{"ID", "USERNAME", "FIRST_NAME", "LAST_NAME", "EMAIL", "LOCALE", "ADMIN", "CREATED_AT"},
+		[][]string{{res.ID, res.Username, res.FirstName, res.LastName, res.Email, res.Locale,
+			strconv.FormatBool(res.IsAdmin), formatTime(res.CreatedAt)}})
+}
@@ -0,0 +1,148 @@
+// tenderctl — административная утилита для операционных задач сервиса тендеров.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"os"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/services"
+
+	"go.uber.org/zap"
+)
+
+const usage = `Использование: tenderctl [-o table|json] <команда> <действие> [флаги] [аргументы]
+
+Команды:
+  org create -name NAME -type IE|LLC|JSC -inn INN -ogrn OGRN [-kpp KPP] [-description TEXT]
+  org add-responsible -org ORG_ID -username USERNAME [-role ROLE]
+  employee create -username USERNAME [-first-name NAME] [-last-name NAME] [-email EMAIL] [-locale ru|en] [-admin]
+  tender list [-limit N] [-offset N]
+  tender get TENDER_ID
+  tender versions TENDER_ID
+  tender set-status -reason TEXT [-actor USERNAME] TENDER_ID STATUS
+  bid list [-limit N] [-offset N] TENDER_ID
+  bid get BID_ID
+  bid versions BID_ID
+  bid set-status -reason TEXT [-actor USERNAME] BID_ID STATUS
+  audit verify TENDER_ID
+  audit head TENDER_ID
+  search reindex
+`
+
+type app struct {
+	out    output
+	logger *zap.Logger
+	user   repositories.User
+	org    repositories.Organization
+	tender repositories.Tender
+	bid    repositories.Bid
+	audit  repositories.Audit
+	search repositories.Search
+	// tenders и bids меняют статус в транзакции с журналом и outbox
+	tenders services.Tender
+	bids    services.Bid
+	key     ed25519.PrivateKey
+}
+
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]map[string]command{
+	"org": {
+		"create":          createOrganization,
+		"add-responsible": addResponsible,
+	},
+	"employee": {
+		"create": createEmployee,
+	},
+	"tender": {
+		"list":       listTenders,
+		"get":        getTender,
+		"versions":   tenderVersions,
+		"set-status": setTenderStatus,
+	},
+	"bid": {
+		"list":       listBids,
+		"get":        getBid,
+		"versions":   bidVersions,
+		"set-status": setBidStatus,
+	},
+	"audit": {
+		"verify": verifyChain,
+		"head":   chainHead,
+	},
+	"search": {
+		"reindex": reindexSearch,
+	},
+}
+
+func main() {
+	format := flag.String("o", "table", "формат вывода: table или json")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintln(os.Stderr, "неизвестный формат вывода:", *format)
+		os.Exit(2)
+	}
+
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// tenderctl — инструмент администратора, RLS для него снят явно
+	ctx := repositories.System(context.Background())
+	logger := zap.Must(zap.NewProduction())
+	db, err := repositories.NewPool(ctx, cfg.Postgres, logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error with db:", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	a := &app{
+		out:    output{w: os.Stdout, json: *format == "json"},
+		logger: logger,
+		user:   repositories.NewUserRepo(db),
+		org:    repositories.NewOrganizationRepo(db),
+		tender: repositories.NewTenderRepo(db),
+		bid:    repositories.NewBidRepo(db),
+		audit:  repositories.NewAuditRepo(db),
+		search: repositories.NewSearchRepo(db),
+	}
+	tx := repositories.NewTxManager(db)
+	events := services.NewOutboxPublisher(repositories.NewOutboxRepo(db))
+	authz := services.NewAuthorizer(a.user, a.tender)
+	a.tenders = services.NewTenderService(a.tender, a.user, a.audit, tx, events, authz)
+	a.bids = services.NewBidService(a.bid, a.user, a.tender, a.audit, repositories.NewKeyRepo(db), tx, events, authz)
+	a.key, _ = cfg.Auth.SigningKey()
+	defer a.logger.Sync()
+
+	if err := cmd(ctx, a, args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// actorID возвращает id сотрудника, от имени которого выполняется команда,
+// или пустую строку, если логин не указан.
+func (a *app) actorID(ctx context.Context, username string) (string, error) {
+	if username == "" {
+		return "", nil
+	}
+	return a.user.GetUserIDByUsername(ctx, username)
+}
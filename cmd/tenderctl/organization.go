@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+func createOrganization(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("org create", flag.ContinueOnError)
+	name := fs.String("name", "", "название организации")
+	typ := fs.String("type", "", "тип организации: IE, LLC или JSC")
+	description := fs.String("description", "", "описание")
+	inn := fs.String("inn", "", "ИНН")
+	ogrn := fs.String("ogrn", "", "ОГРН или ОГРНИП")
+	kpp := fs.String("kpp", "", "КПП, кроме IE")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	org := entities.Organization{Name: *name, Description: *description, INN: *inn, OGRN: *ogrn, KPP: *kpp}
+	org.Type.Scan(*typ)
+	if org.Name == "" || org.Type == "" {
+		return errors.New("нужно указать -name и -type (IE, LLC или JSC)")
+	}
+	if !org.ValidRequisites() {
+		return errors.New("у IE нужны -inn из 12 цифр и -ogrn из 15, у LLC и JSC — -inn из 10 цифр, -ogrn из 13 и -kpp")
+	}
+	res, err := a.org.Create(ctx, org)
+	if err != nil {
+		return err
+	}
+	return a.out.print(res,
+		[]string{"ID", "NAME", "TYPE", "INN", "OGRN", "KPP", "CREATED_AT"},
+		[][]string{{res.ID, res.Name, string(res.Type), res.INN, res.OGRN, res.KPP, formatTime(res.CreatedAt)}})
+}
+
+func addResponsible(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("org add-responsible", flag.ContinueOnError)
+	orgID := fs.String("org", "", "id организации")
+	username := fs.String("username", "", "логин сотрудника")
+	roleName := fs.String("role", "owner", "роль: owner, procurement_manager, evaluator, viewer или bidder")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *orgID == "" || *username == "" {
+		return errors.New("нужно указать -org и -username")
+	}
+	var role entities.OrganizationRole
+	role.Scan(*roleName)
+	if role == "" {
+		return errors.New("-role — owner, procurement_manager, evaluator, viewer или bidder")
+	}
+	userID, err := a.user.GetUserIDByUsername(ctx, *username)
+	if err != nil {
+		return err
+	}
+	if err := a.org.AddResponsible(ctx, *orgID, userID, role); err != nil {
+		return err
+	}
+	res := map[string]string{"organization_id": *orgID, "user_id": userID, "role": string(role)}
+	return a.out.print(res,
+		[]string{"ORGANIZATION_ID", "USER_ID", "ROLE"},
+		[][]string{{*orgID, userID, string(role)}})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"time"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+var tenderHeader = []string{"ID", "NAME", "SERVICE_TYPE", "STATUS", "VERSION", "CREATED_AT"}
+
+func tenderRow(t entities.Tender) []string {
+	return []string{t.ID, t.Name, string(t.ServiceType), string(t.Status),
+		strconv.Itoa(int(t.Version)), formatTime(t.CreatedAt)}
+}
+
+func listTenders(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("tender list", flag.ContinueOnError)
+	limit := fs.Uint("limit", 50, "количество тендеров")
+	offset := fs.Uint("offset", 0, "смещение")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tenders, err := a.tender.GetTenderList(ctx, operation.TenderListParams{
+		Limit:  uint32(*limit),
+		Offset: uint32(*offset),
+	}, entities.Viewer{All: true})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(tenders))
+	for _, t := range tenders {
+		rows = append(rows, tenderRow(t))
+	}
+	return a.out.print(tenders, tenderHeader, rows)
+}
+
+func getTender(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("нужно указать id тендера")
+	}
+	t, err := a.tender.GetByID(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	header := append(tenderHeader, "ORGANIZATION_ID", "CREATOR")
+	row := append(tenderRow(t), t.OrganizationID, t.CreatorUsername)
+	return a.out.print(t, header, [][]string{row})
+}
+
+// setTenderStatus меняет статус в обход проверок прав сервиса,
+// поэтому причина обязательна и попадает в журнал аудита.
+func setTenderStatus(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("tender set-status", flag.ContinueOnError)
+	reason := fs.String("reason", "", "причина изменения")
+	actor := fs.String("actor", "", "логин сотрудника, от имени которого вносится изменение")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 || *reason == "" {
+		return errors.New("нужно указать -reason, id тендера и статус")
+	}
+	var status entities.TenderStatus
+	status.Scan(fs.Arg(1))
+	if status == "" {
+		return errors.New("неизвестный статус тендера: " + fs.Arg(1))
+	}
+	actorId, err := a.actorID(ctx, *actor)
+	if err != nil {
+		return err
+	}
+	before, err := a.tender.GetByID(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	t, err := a.tenders.ForceTenderStatus(ctx, status, fs.Arg(0), actorId, *reason)
+	if err != nil {
+		return err
+	}
+	a.logger.Info("tender status forced",
+		zap.String("tender_id", t.ID),
+		zap.String("from", string(before.Status)),
+		zap.String("to", string(status)),
+		zap.String("reason", *reason))
+	return a.out.print(t, tenderHeader, [][]string{tenderRow(t)})
+}
+
+func tenderVersions(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("нужно указать id тендера")
+	}
+	return printVersions(ctx, a, entities.AuditEntityTender, args[0])
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.DateTime)
+}
+
+func reindexSearch(ctx context.Context, a *app, args []string) error {
+	if len(args) != 0 {
+		return errors.New("reindex не принимает аргументов")
+	}
+	start := time.Now()
+	if err := a.search.Reindex(ctx); err != nil {
+		return err
+	}
+	a.logger.Info("search reindexed", zap.Duration("duration", time.Since(start)))
+	return nil
+}
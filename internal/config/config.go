@@ -0,0 +1,439 @@
+// Package config собирает настройки сервиса из значений по умолчанию,
+// необязательного env-файла, переменных окружения и флагов — именно в таком
+// порядке, каждый следующий источник перекрывает предыдущий.
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	netmail "net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultFile = "cmd/main.env"
+
+type Config struct {
+	Server   Server
+	Postgres Postgres
+	Log      Log
+	Auth     Auth
+	Features Features
+	Tracing  Tracing
+	Workers  Workers
+	Outbox   Outbox
+	Mail     Mail
+
+	File        string
+	PrintConfig bool
+}
+
+type Server struct {
+	Address         string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+}
+
+type Postgres struct {
+	Conn             string
+	MaxConns         int
+	MinConns         int
+	MaxConnIdleTime  time.Duration
+	MaxConnLifetime  time.Duration
+	StatementTimeout time.Duration
+	ConnectTimeout   time.Duration
+}
+
+type Log struct {
+	Level string
+}
+
+type Auth struct {
+	// AuditSigningKey — seed ключа Ed25519 в base64, которым подписывается
+	// голова цепочки журнала изменений
+	AuditSigningKey string
+}
+
+// SigningKey возвращает ключ подписи журнала или nil, если он не задан.
+func (a Auth) SigningKey() (ed25519.PrivateKey, error) {
+	if a.AuditSigningKey == "" {
+		return nil, nil
+	}
+	seed, err := base64.StdEncoding.DecodeString(a.AuditSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("ожидается %d байт, получено %d", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+type Tracing struct {
+	Exporter    string
+	Endpoint    string
+	SampleRatio float64
+}
+
+// Workers — периоды фоновых воркеров.
+type Workers struct {
+	SealedSweepInterval time.Duration
+	WebhookInterval     time.Duration
+	OutboxInterval      time.Duration
+	MailInterval        time.Duration
+	DigestInterval      time.Duration
+	DeadlineInterval    time.Duration
+}
+
+// Outbox — получатели доменных событий.
+type Outbox struct {
+	// Sinks — список через запятую: log, webhook, bus, nats
+	Sinks       string
+	NATSURL     string
+	NATSSubject string
+}
+
+// SinkList возвращает получателей без пробелов и пустых элементов.
+func (o Outbox) SinkList() []string {
+	var res []string
+	for _, s := range strings.Split(o.Sinks, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Mail — отправка писем.
+type Mail struct {
+	// Transport — none, log или smtp
+	Transport    string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTimeout  time.Duration
+	From         string
+	// DeadlineReminder — за сколько до bid_deadline напоминать о закрытом тендере
+	DeadlineReminder time.Duration
+}
+
+type Features struct {
+	MigrateOnStart bool
+}
+
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Address:         ":8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Postgres: Postgres{
+			MaxConns:         10,
+			MinConns:         0,
+			MaxConnIdleTime:  30 * time.Minute,
+			MaxConnLifetime:  time.Hour,
+			StatementTimeout: 30 * time.Second,
+			ConnectTimeout:   time.Minute,
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Workers: Workers{
+			SealedSweepInterval: time.Minute,
+			WebhookInterval:     5 * time.Second,
+			OutboxInterval:      time.Second,
+			MailInterval:        10 * time.Second,
+			DigestInterval:      time.Hour,
+			DeadlineInterval:    5 * time.Minute,
+		},
+		Outbox: Outbox{
+			Sinks:       "webhook",
+			NATSURL:     "nats://localhost:4222",
+			NATSSubject: "tender.events",
+		},
+		Mail: Mail{
+			Transport:        "none",
+			SMTPAddr:         "localhost:1025",
+			SMTPTimeout:      10 * time.Second,
+			From:             "tenders@localhost",
+			DeadlineReminder: 24 * time.Hour,
+		},
+	}
+}
+
+// setting связывает поле конфигурации с переменной окружения и флагом.
+type setting struct {
+	env    string
+	flag   string
+	usage  string
+	secret bool
+}
+
+func (c *Config) settings(fs *flag.FlagSet) []setting {
+	fs.StringVar(&c.Server.Address, "SERVER_ADDRESS", c.Server.Address, "")
+	fs.DurationVar(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT", c.Server.ReadTimeout, "")
+	fs.DurationVar(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout, "")
+	fs.DurationVar(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout, "")
+	fs.DurationVar(&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout, "")
+	fs.StringVar(&c.Postgres.Conn, "POSTGRES_CONN", c.Postgres.Conn, "")
+	fs.IntVar(&c.Postgres.MaxConns, "POSTGRES_MAX_CONNS", c.Postgres.MaxConns, "")
+	fs.IntVar(&c.Postgres.MinConns, "POSTGRES_MIN_CONNS", c.Postgres.MinConns, "")
+	fs.DurationVar(&c.Postgres.MaxConnIdleTime, "POSTGRES_MAX_CONN_IDLE_TIME", c.Postgres.MaxConnIdleTime, "")
+	fs.DurationVar(&c.Postgres.MaxConnLifetime, "POSTGRES_MAX_CONN_LIFETIME", c.Postgres.MaxConnLifetime, "")
+	fs.DurationVar(&c.Postgres.StatementTimeout, "POSTGRES_STATEMENT_TIMEOUT", c.Postgres.StatementTimeout, "")
+	fs.DurationVar(&c.Postgres.ConnectTimeout, "POSTGRES_CONNECT_TIMEOUT", c.Postgres.ConnectTimeout, "")
+	fs.StringVar(&c.Log.Level, "LOG_LEVEL", c.Log.Level, "")
+	fs.StringVar(&c.Auth.AuditSigningKey, "AUDIT_SIGNING_KEY", c.Auth.AuditSigningKey, "")
+	fs.BoolVar(&c.Features.MigrateOnStart, "MIGRATE_ON_START", c.Features.MigrateOnStart, "")
+	fs.StringVar(&c.Tracing.Exporter, "TRACING_EXPORTER", c.Tracing.Exporter, "")
+	fs.StringVar(&c.Tracing.Endpoint, "TRACING_ENDPOINT", c.Tracing.Endpoint, "")
+	fs.Float64Var(&c.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO", c.Tracing.SampleRatio, "")
+	fs.DurationVar(&c.Workers.SealedSweepInterval, "WORKER_SEALED_SWEEP_INTERVAL", c.Workers.SealedSweepInterval, "")
+	fs.DurationVar(&c.Workers.WebhookInterval, "WORKER_WEBHOOK_INTERVAL", c.Workers.WebhookInterval, "")
+	fs.DurationVar(&c.Workers.OutboxInterval, "WORKER_OUTBOX_INTERVAL", c.Workers.OutboxInterval, "")
+	fs.StringVar(&c.Outbox.Sinks, "OUTBOX_SINKS", c.Outbox.Sinks, "")
+	fs.StringVar(&c.Outbox.NATSURL, "NATS_URL", c.Outbox.NATSURL, "")
+	fs.StringVar(&c.Outbox.NATSSubject, "NATS_SUBJECT", c.Outbox.NATSSubject, "")
+	fs.DurationVar(&c.Workers.MailInterval, "WORKER_MAIL_INTERVAL", c.Workers.MailInterval, "")
+	fs.DurationVar(&c.Workers.DigestInterval, "WORKER_DIGEST_INTERVAL", c.Workers.DigestInterval, "")
+	fs.DurationVar(&c.Workers.DeadlineInterval, "WORKER_DEADLINE_INTERVAL", c.Workers.DeadlineInterval, "")
+	fs.StringVar(&c.Mail.Transport, "MAIL_TRANSPORT", c.Mail.Transport, "")
+	fs.StringVar(&c.Mail.SMTPAddr, "SMTP_ADDR", c.Mail.SMTPAddr, "")
+	fs.StringVar(&c.Mail.SMTPUsername, "SMTP_USERNAME", c.Mail.SMTPUsername, "")
+	fs.StringVar(&c.Mail.SMTPPassword, "SMTP_PASSWORD", c.Mail.SMTPPassword, "")
+	fs.DurationVar(&c.Mail.SMTPTimeout, "SMTP_TIMEOUT", c.Mail.SMTPTimeout, "")
+	fs.StringVar(&c.Mail.From, "MAIL_FROM", c.Mail.From, "")
+	fs.DurationVar(&c.Mail.DeadlineReminder, "MAIL_DEADLINE_REMINDER", c.Mail.DeadlineReminder, "")
+
+	return []setting{
+		{env: "SERVER_ADDRESS", flag: "addr", usage: "адрес HTTP-сервера"},
+		{env: "SERVER_READ_TIMEOUT", flag: "read-timeout", usage: "таймаут чтения запроса"},
+		{env: "SERVER_WRITE_TIMEOUT", flag: "write-timeout", usage: "таймаут записи ответа"},
+		{env: "SERVER_IDLE_TIMEOUT", flag: "idle-timeout", usage: "таймаут keep-alive соединения"},
+		{env: "SERVER_SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "время на завершение запросов при остановке"},
+		{env: "POSTGRES_CONN", flag: "postgres-conn", usage: "строка подключения к PostgreSQL", secret: true},
+		{env: "POSTGRES_MAX_CONNS", flag: "postgres-max-conns", usage: "максимум соединений в пуле"},
+		{env: "POSTGRES_MIN_CONNS", flag: "postgres-min-conns", usage: "минимум соединений в пуле"},
+		{env: "POSTGRES_MAX_CONN_IDLE_TIME", flag: "postgres-max-conn-idle-time", usage: "время простоя, после которого соединение закрывается"},
+		{env: "POSTGRES_MAX_CONN_LIFETIME", flag: "postgres-max-conn-lifetime", usage: "максимальное время жизни соединения"},
+		{env: "POSTGRES_STATEMENT_TIMEOUT", flag: "postgres-statement-timeout", usage: "statement_timeout для запросов, 0 — без ограничения"},
+		{env: "POSTGRES_CONNECT_TIMEOUT", flag: "postgres-connect-timeout", usage: "сколько ждать доступности базы при запуске"},
+		{env: "LOG_LEVEL", flag: "log-level", usage: "уровень логирования: debug, info, warn, error"},
+		{env: "AUDIT_SIGNING_KEY", flag: "audit-signing-key", usage: "seed ключа Ed25519 в base64 для подписи цепочки журнала", secret: true},
+		{env: "MIGRATE_ON_START", flag: "migrate", usage: "применить миграции при запуске"},
+		{env: "TRACING_EXPORTER", flag: "tracing-exporter", usage: "экспорт трейсов: none, stdout или otlp"},
+		{env: "TRACING_ENDPOINT", flag: "tracing-endpoint", usage: "адрес OTLP/HTTP коллектора, например http://localhost:4318"},
+		{env: "TRACING_SAMPLE_RATIO", flag: "tracing-sample-ratio", usage: "доля сохраняемых трейсов от 0 до 1"},
+		{env: "WORKER_SEALED_SWEEP_INTERVAL", flag: "sealed-sweep-interval", usage: "как часто дисквалифицировать нераскрытые предложения закрытых тендеров"},
+		{env: "WORKER_WEBHOOK_INTERVAL", flag: "webhook-interval", usage: "как часто отправлять вебхуки из очереди"},
+		{env: "WORKER_OUTBOX_INTERVAL", flag: "outbox-interval", usage: "как часто публиковать события из outbox"},
+		{env: "OUTBOX_SINKS", flag: "outbox-sinks", usage: "получатели событий через запятую: log, webhook, bus, nats"},
+		{env: "NATS_URL", flag: "nats-url", usage: "адрес NATS для получателя nats", secret: true},
+		{env: "NATS_SUBJECT", flag: "nats-subject", usage: "префикс subject событий в NATS"},
+		{env: "WORKER_MAIL_INTERVAL", flag: "mail-interval", usage: "как часто отправлять письма из очереди"},
+		{env: "WORKER_DIGEST_INTERVAL", flag: "digest-interval", usage: "как часто проверять, кому пора отправить сводку"},
+		{env: "WORKER_DEADLINE_INTERVAL", flag: "deadline-interval", usage: "как часто искать закрытые тендеры с близким сроком подачи"},
+		{env: "MAIL_TRANSPORT", flag: "mail-transport", usage: "отправка писем: none, log или smtp"},
+		{env: "SMTP_ADDR", flag: "smtp-addr", usage: "адрес SMTP-сервера host:port"},
+		{env: "SMTP_USERNAME", flag: "smtp-username", usage: "логин SMTP, пустой — без авторизации"},
+		{env: "SMTP_PASSWORD", flag: "smtp-password", usage: "пароль SMTP", secret: true},
+		{env: "SMTP_TIMEOUT", flag: "smtp-timeout", usage: "таймаут отправки одного письма"},
+		{env: "MAIL_FROM", flag: "mail-from", usage: "адрес отправителя писем"},
+		{env: "MAIL_DEADLINE_REMINDER", flag: "mail-deadline-reminder", usage: "за сколько до срока подачи напоминать о закрытом тендере"},
+	}
+}
+
+// Load читает конфигурацию. args — аргументы командной строки без имени
+// программы; оставшиеся после флагов аргументы возвращаются вторым значением.
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	bind := flag.NewFlagSet("config", flag.ContinueOnError)
+	settings := cfg.settings(bind)
+
+	cli := flag.NewFlagSet("tender_service", flag.ContinueOnError)
+	cli.StringVar(&cfg.File, "config", "", "env-файл с настройками (по умолчанию "+defaultFile+", если существует)")
+	cli.BoolVar(&cfg.PrintConfig, "print-config", false, "вывести итоговую конфигурацию и выйти")
+	flags := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flags[s.flag] = cli.String(s.flag, "", s.usage+" ("+s.env+")")
+	}
+	if err := cli.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	file := cfg.File
+	if file == "" {
+		if _, err := os.Stat(defaultFile); err == nil {
+			file = defaultFile
+		}
+	}
+	if file != "" {
+		values, err := godotenv.Read(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("config: %s: %w", file, err)
+		}
+		for _, s := range settings {
+			if v, ok := values[s.env]; ok {
+				if err := bind.Set(s.env, v); err != nil {
+					return nil, nil, fmt.Errorf("config: %s: %s: %w", file, s.env, err)
+				}
+			}
+		}
+		cfg.File = file
+	}
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := bind.Set(s.env, v); err != nil {
+				return nil, nil, fmt.Errorf("config: $%s: %w", s.env, err)
+			}
+		}
+	}
+	var err error
+	cli.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && err == nil {
+				if e := bind.Set(s.env, *flags[s.flag]); e != nil {
+					err = fmt.Errorf("config: -%s: %w", s.flag, e)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	// -print-config нужен и для разбора неверной конфигурации, поэтому
+	// проверку тогда делает вызывающий после вывода.
+	if cfg.PrintConfig {
+		return cfg, cli.Args(), nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, cli.Args(), nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Address == "" {
+		errs = append(errs, errors.New("SERVER_ADDRESS не задан"))
+	}
+	if c.Postgres.Conn == "" {
+		errs = append(errs, errors.New("POSTGRES_CONN не задан"))
+	}
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"POSTGRES_MAX_CONN_IDLE_TIME", c.Postgres.MaxConnIdleTime},
+		{"POSTGRES_MAX_CONN_LIFETIME", c.Postgres.MaxConnLifetime},
+		{"POSTGRES_CONNECT_TIMEOUT", c.Postgres.ConnectTimeout},
+		{"WORKER_SEALED_SWEEP_INTERVAL", c.Workers.SealedSweepInterval},
+		{"WORKER_WEBHOOK_INTERVAL", c.Workers.WebhookInterval},
+		{"WORKER_OUTBOX_INTERVAL", c.Workers.OutboxInterval},
+		{"WORKER_MAIL_INTERVAL", c.Workers.MailInterval},
+		{"WORKER_DIGEST_INTERVAL", c.Workers.DigestInterval},
+		{"WORKER_DEADLINE_INTERVAL", c.Workers.DeadlineInterval},
+		{"SMTP_TIMEOUT", c.Mail.SMTPTimeout},
+		{"MAIL_DEADLINE_REMINDER", c.Mail.DeadlineReminder},
+	}
+	for _, t := range timeouts {
+		if t.d <= 0 {
+			errs = append(errs, fmt.Errorf("%s должен быть больше нуля", t.name))
+		}
+	}
+	if c.Postgres.StatementTimeout < 0 {
+		errs = append(errs, errors.New("POSTGRES_STATEMENT_TIMEOUT не может быть отрицательным"))
+	}
+	if c.Postgres.MaxConns <= 0 {
+		errs = append(errs, errors.New("POSTGRES_MAX_CONNS должен быть больше нуля"))
+	}
+	if c.Postgres.MinConns < 0 || c.Postgres.MinConns > c.Postgres.MaxConns {
+		errs = append(errs, errors.New("POSTGRES_MIN_CONNS должен быть от 0 до POSTGRES_MAX_CONNS"))
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER: неизвестное значение %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("TRACING_SAMPLE_RATIO должен быть от 0 до 1"))
+	}
+	for _, sink := range c.Outbox.SinkList() {
+		switch sink {
+		case "log", "webhook", "bus":
+		case "nats":
+			if c.Outbox.NATSURL == "" || c.Outbox.NATSSubject == "" {
+				errs = append(errs, errors.New("для OUTBOX_SINKS=nats нужны NATS_URL и NATS_SUBJECT"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("OUTBOX_SINKS: неизвестный получатель %q", sink))
+		}
+	}
+	switch c.Mail.Transport {
+	case "none", "log":
+	case "smtp":
+		if c.Mail.SMTPAddr == "" {
+			errs = append(errs, errors.New("для MAIL_TRANSPORT=smtp нужен SMTP_ADDR"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_TRANSPORT: неизвестное значение %q", c.Mail.Transport))
+	}
+	if c.Mail.Transport != "none" {
+		if _, err := netmail.ParseAddress(c.Mail.From); err != nil {
+			errs = append(errs, fmt.Errorf("MAIL_FROM: %w", err))
+		}
+	}
+	if _, err := c.Auth.SigningKey(); err != nil {
+		errs = append(errs, fmt.Errorf("AUDIT_SIGNING_KEY: %w", err))
+	}
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// Print выводит итоговую конфигурацию в формате env-файла, скрывая секреты.
+func (c *Config) Print(w io.Writer) {
+	bind := flag.NewFlagSet("config", flag.ContinueOnError)
+	for _, s := range c.settings(bind) {
+		v := bind.Lookup(s.env).Value.String()
+		if s.secret && v != "" {
+			v = redact(v)
+		}
+		fmt.Fprintf(w, "%s=%s\n", s.env, v)
+	}
+}
+
+// redact оставляет от строки подключения все, кроме пароля,
+// остальные секреты скрывает целиком.
+func redact(v string) string {
+	if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.User != nil {
+		return u.Redacted()
+	}
+	return "***"
+}
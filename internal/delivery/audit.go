@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"go.uber.org/zap"
+)
+
+type AuditHandler struct {
+	service services.Audit
+	logger  *zap.Logger
+}
+
+func NewAuditHandler(service services.Audit, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *AuditHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *AuditHandler) GetEntries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	var entity entities.AuditEntity
+	entity.Scan(params.Get("entity"))
+	id := params.Get("id")
+	if entity == "" || id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	entries, err := h.service.GetEntries(r.Context(), entity, id, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(entries)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *AuditHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	tenderId := params.Get("tenderId")
+	if tenderId == "" {
+		operation.BadRequest(w)
+		return
+	}
+	report, err := h.service.VerifyChain(r.Context(), tenderId, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	if !report.Valid {
+		h.log(r).Warn("audit chain is broken",
+			zap.String("tender_id", tenderId),
+			zap.Int64("seq", report.BrokenAt.Seq),
+			zap.String("reason", report.BrokenAt.Reason))
+	}
+	response, err := json.Marshal(report)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *AuditHandler) ChainHead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	tenderId := params.Get("tenderId")
+	if tenderId == "" {
+		operation.BadRequest(w)
+		return
+	}
+	head, err := h.service.ChainHead(r.Context(), tenderId, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(head)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
@@ -2,9 +2,9 @@ package delivery
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
 	"zadanie-6105/internal/delivery/operation"
 	"zadanie-6105/internal/repositories/entities"
 	"zadanie-6105/internal/services"
@@ -25,13 +25,17 @@ func NewBidHandler(service services.Bid, logger *zap.Logger) *BidHandler {
 	}
 }
 
+func (h *BidHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
 func (h *BidHandler) GetUserBids(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	params := r.URL.Query()
 	filterParams := operation.BidParams{}
 	err := filterParams.Scan(params.Get("limit"), params.Get("offset"))
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -42,13 +46,13 @@ func (h *BidHandler) GetUserBids(w http.ResponseWriter, r *http.Request) {
 	}
 	bids, err := h.service.GetUserBids(r.Context(), filterParams, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(bids)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -67,7 +71,7 @@ func (h *BidHandler) GetBidsForTender(w http.ResponseWriter, r *http.Request) {
 	}
 	err := filterParams.Scan(params.Get("limit"), params.Get("offset"))
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -76,15 +80,15 @@ func (h *BidHandler) GetBidsForTender(w http.ResponseWriter, r *http.Request) {
 		operation.Unauthorized(w)
 		return
 	}
-	bids, err := h.service.GetBidsForTender(r.Context(), id, filterParams)
+	bids, err := h.service.GetBidsForTender(r.Context(), id, filterParams, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(bids)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -95,7 +99,7 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -104,19 +108,19 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 	b := entities.Bid{}
 	err = json.Unmarshal(body, &b)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
 	bid, err := h.service.CreateBid(r.Context(), b)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(bid)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -137,15 +141,15 @@ func (h *BidHandler) GetBidStatus(w http.ResponseWriter, r *http.Request) {
 		operation.BadRequest(w)
 		return
 	}
-	status, err := h.service.GetBidStatus(r.Context(), id)
+	status, err := h.service.GetBidStatus(r.Context(), id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(status)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -173,15 +177,15 @@ func (h *BidHandler) ChangeBidStatus(w http.ResponseWriter, r *http.Request) {
 		operation.BadRequest(w)
 		return
 	}
-	bid, err := h.service.ChangeBidStatus(r.Context(), status, id)
+	bid, err := h.service.ChangeBidStatus(r.Context(), status, id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(bid)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -211,17 +215,13 @@ func (h *BidHandler) SubmitBid(w http.ResponseWriter, r *http.Request) {
 	}
 	bid, err := h.service.SubmitBid(r.Context(), status, id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		if errors.Is(err, services.ErrNoAccess) || errors.Is(err, services.ErrNotResponsible) {
-			operation.Forbidden(w)
-			return
-		}
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(bid)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -244,7 +244,7 @@ func (h *BidHandler) EditBid(w http.ResponseWriter, r *http.Request) {
 	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -253,19 +253,95 @@ func (h *BidHandler) EditBid(w http.ResponseWriter, r *http.Request) {
 	t := entities.Bid{}
 	err = json.Unmarshal(body, &t)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
-	bid, err := h.service.EditBid(r.Context(), t, id)
+	bid, err := h.service.EditBid(r.Context(), t, id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(bid)
+	if err != nil {
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
+	w.Write(response)
+}
+
+func (h *BidHandler) GetBidSignatures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	vars := mux.Vars(r)
+	id := vars["bidId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	signatures, err := h.service.GetBidSignatures(r.Context(), id, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(signatures)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *BidHandler) RevealBid(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	vars := mux.Vars(r)
+	id := vars["bidId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	req := struct {
+		Price string `json:"price"`
+		Salt  string `json:"salt"`
+	}{}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	bid, err := h.service.RevealBid(r.Context(), id, req.Price, req.Salt, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
 	response, err := json.Marshal(bid)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type EmployeeHandler struct {
+	service services.Employee
+	logger  *zap.Logger
+}
+
+func NewEmployeeHandler(service services.Employee, logger *zap.Logger) *EmployeeHandler {
+	return &EmployeeHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *EmployeeHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+// CreateEmployee — регистрация, поэтому username в запросе не нужен.
+func (h *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	e := entities.Employee{}
+	err = json.Unmarshal(body, &e)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	employee, err := h.service.CreateEmployee(r.Context(), e)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(employee)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *EmployeeHandler) EditEmployee(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["employeeId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	e := entities.Employee{}
+	err = json.Unmarshal(body, &e)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	employee, err := h.service.EditEmployee(r.Context(), e, id, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(employee)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
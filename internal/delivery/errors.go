@@ -0,0 +1,55 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/services"
+)
+
+// writeError отвечает клиенту кодом, соответствующим ошибке сервиса.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		operation.Unauthorized(w)
+	case errors.Is(err, services.ErrNotResponsible), errors.Is(err, services.ErrNoAccess),
+		errors.Is(err, services.ErrNotAdmin), errors.Is(err, services.ErrPermissionDenied):
+		operation.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidDecision):
+		operation.BadRequest(w)
+	case errors.Is(err, services.ErrInvalidKey), errors.Is(err, services.ErrInvalidSignature):
+		operation.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrInvalidDeadlines), errors.Is(err, services.ErrInvalidCommitment),
+		errors.Is(err, services.ErrNotSealed), errors.Is(err, services.ErrInvalidReveal),
+		errors.Is(err, services.ErrCommitmentMismatch), errors.Is(err, services.ErrInvalidStatus),
+		errors.Is(err, services.ErrInvalidBudget), errors.Is(err, services.ErrInvalidSearch),
+		errors.Is(err, services.ErrInvalidFilter), errors.Is(err, services.ErrInvalidOrganization),
+		errors.Is(err, services.ErrInvalidEmployee), errors.Is(err, services.ErrInvalidRequisites),
+		errors.Is(err, services.ErrInvalidRole):
+		operation.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrBiddingClosed), errors.Is(err, services.ErrRevealWindow),
+		errors.Is(err, services.ErrAlreadyRevealed), errors.Is(err, services.ErrRevealPending),
+		errors.Is(err, services.ErrTooManySearches), errors.Is(err, services.ErrOrganizationInUse),
+		errors.Is(err, services.ErrUsernameTaken), errors.Is(err, services.ErrAlreadyResponsible),
+		errors.Is(err, services.ErrLastOwner), errors.Is(err, services.ErrLastResponsible),
+		errors.Is(err, services.ErrRequisitesTaken), errors.Is(err, services.ErrBidDecided),
+		errors.Is(err, services.ErrTenderNotOpen), errors.Is(err, services.ErrNotRevealable):
+		operation.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidWebhook), errors.Is(err, services.ErrWebhookAddress),
+		errors.Is(err, services.ErrInvalidPreferences), errors.Is(err, services.ErrInvalidMailSettings):
+		operation.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrKeyNotFound), errors.Is(err, services.ErrWebhookNotFound),
+		errors.Is(err, services.ErrDeliveryNotFound), errors.Is(err, services.ErrNotificationNotFound),
+		errors.Is(err, services.ErrSearchNotFound), errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrEmployeeNotFound), errors.Is(err, services.ErrResponsibleNotFound),
+		errors.Is(err, services.ErrInvitationNotFound), errors.Is(err, services.ErrTenderNotFound),
+		errors.Is(err, services.ErrBidNotFound):
+		operation.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrStreamBacklog):
+		operation.Error(w, http.StatusGone, err.Error())
+	case errors.Is(err, services.ErrSigningDisabled):
+		operation.Error(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		operation.InternalServerError(w)
+	}
+}
@@ -0,0 +1,51 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/health"
+
+	"go.uber.org/zap"
+)
+
+const healthTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	checker *health.Checker
+	logger  *zap.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+		logger:  logger,
+	}
+}
+
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.write(w, h.checker.Liveness(r.Context()))
+}
+
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+	defer cancel()
+	h.write(w, h.checker.Readiness(ctx))
+}
+
+func (h *HealthHandler) write(w http.ResponseWriter, report health.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	response, err := json.Marshal(report)
+	if err != nil {
+		h.logger.Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	code := http.StatusOK
+	if !report.OK() {
+		code = http.StatusServiceUnavailable
+	}
+	operation.WriteResponse(w, code, response)
+}
@@ -0,0 +1,116 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/services"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type KeyHandler struct {
+	service services.Key
+	logger  *zap.Logger
+}
+
+func NewKeyHandler(service services.Key, logger *zap.Logger) *KeyHandler {
+	return &KeyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *KeyHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *KeyHandler) RegisterKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	creator := r.URL.Query().Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	req := struct {
+		PublicKey string `json:"public_key"`
+	}{}
+	err = json.Unmarshal(body, &req)
+	if err != nil || req.PublicKey == "" {
+		operation.BadRequest(w)
+		return
+	}
+	key, err := h.service.RegisterKey(r.Context(), req.PublicKey, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(key)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *KeyHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	creator := r.URL.Query().Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	keys, err := h.service.GetKeys(r.Context(), creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(keys)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *KeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	creator := r.URL.Query().Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["keyId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	key, err := h.service.RevokeKey(r.Context(), id, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(key)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
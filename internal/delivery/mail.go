@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"go.uber.org/zap"
+)
+
+type MailHandler struct {
+	service services.Mail
+	logger  *zap.Logger
+}
+
+func NewMailHandler(service services.Mail, logger *zap.Logger) *MailHandler {
+	return &MailHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *MailHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *MailHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	settings, err := h.service.GetSettings(r.Context(), username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(settings)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *MailHandler) SetSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	settings := entities.MailSettings{}
+	err = json.Unmarshal(body, &settings)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	res, err := h.service.SetSettings(r.Context(), settings, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(res)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Logging кладет в контекст логгер запроса и пишет одну строку
+// access-лога на каждый запрос.
+func Logging(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			route := RouteTemplate(r)
+			fields := []zap.Field{
+				zap.String("request_id", RequestIDFromContext(r.Context())),
+				zap.String("method", r.Method),
+				zap.String("route", route),
+			}
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+			}
+			reqLogger := logger.With(fields...)
+			ctx := context.WithValue(r.Context(), loggerKey, reqLogger)
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			reqLogger.Info("request",
+				zap.Int("status", rec.status),
+				zap.Int("bytes", rec.bytes),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("user", r.URL.Query().Get("username")),
+			)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Package middleware содержит HTTP-middleware сервиса.
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"zadanie-6105/internal/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// Metrics замеряет длительность запросов. Маршрут берется из шаблона mux,
+// а не из пути, чтобы id в URL не раздували число серий.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := newResponseRecorder(w)
+		next.ServeHTTP(rec, r)
+		metrics.HTTPRequestDuration.
+			WithLabelValues(r.Method, RouteTemplate(r), strconv.Itoa(rec.status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+func RouteTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	return tpl
+}
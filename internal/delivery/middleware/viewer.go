@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+type ViewerResolver interface {
+	Viewer(ctx context.Context, username string) (entities.Viewer, error)
+}
+
+// Viewer выполняет обращения запроса к базе от имени сотрудника из параметра
+// username, см. repositories.WithViewer. Без username или с неизвестным
+// логином запрос идет от анонима, а отказ вернет сам обработчик.
+func Viewer(users ViewerResolver, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			viewer, err := users.Viewer(r.Context(), r.URL.Query().Get("username"))
+			if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+				LoggerFromContext(r.Context(), logger).Error(err.Error())
+				operation.InternalServerError(w)
+				return
+			}
+			ctx := repositories.WithViewer(r.Context(), viewer)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
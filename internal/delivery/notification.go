@@ -0,0 +1,181 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type NotificationHandler struct {
+	service services.Notification
+	logger  *zap.Logger
+}
+
+func NewNotificationHandler(service services.Notification, logger *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *NotificationHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	username := params.Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	page := operation.BidParams{}
+	if err := page.Scan(params.Get("limit"), params.Get("offset")); err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	if page.Limit <= 0 {
+		page.Limit = 50
+	}
+	var unread bool
+	if v := params.Get("unread"); v != "" {
+		var err error
+		if unread, err = strconv.ParseBool(v); err != nil {
+			operation.BadRequest(w)
+			return
+		}
+	}
+	notifications, err := h.service.GetNotifications(r.Context(), username, unread, page.Limit, page.Offset)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(notifications)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["notificationId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	notification, err := h.service.MarkRead(r.Context(), id, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(notification)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	count, err := h.service.MarkAllRead(r.Context(), username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(struct {
+		Marked int `json:"marked"`
+	}{count})
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	prefs, err := h.service.GetPreferences(r.Context(), username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(prefs)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *NotificationHandler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	prefs := entities.NotificationPreferences{}
+	err = json.Unmarshal(body, &prefs)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	res, err := h.service.SetPreferences(r.Context(), prefs, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(res)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
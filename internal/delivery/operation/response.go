@@ -1,29 +1,43 @@
 package operation
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+)
 
-func BadRequest(w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusBadRequest)
-	w.Write([]byte(`{"reason": "Неверный формат запроса или его параметры"}`))
+// RequestIDHeader — заголовок с id запроса, его выставляет middleware.RequestID.
+const RequestIDHeader = "X-Request-ID"
+
+type errorResponse struct {
+	Reason    string `json:"reason"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error пишет ответ с причиной ошибки и id запроса, если он известен.
+func Error(w http.ResponseWriter, code int, reason string) http.ResponseWriter {
+	response, _ := json.Marshal(errorResponse{
+		Reason:    reason,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+	w.WriteHeader(code)
+	w.Write(response)
 	return w
 }
 
+func BadRequest(w http.ResponseWriter) http.ResponseWriter {
+	return Error(w, http.StatusBadRequest, "Неверный формат запроса или его параметры")
+}
+
 func InternalServerError(w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusInternalServerError)
-	w.Write([]byte(`{"reason": "Ошибка сервера"}`))
-	return w
+	return Error(w, http.StatusInternalServerError, "Ошибка сервера")
 }
 
 func Unauthorized(w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusUnauthorized)
-	w.Write([]byte(`{"reason": "Пользователь не существует или некорректен"}`))
-	return w
+	return Error(w, http.StatusUnauthorized, "Пользователь не существует или некорректен")
 }
 
 func Forbidden(w http.ResponseWriter) http.ResponseWriter {
-	w.WriteHeader(http.StatusForbidden)
-	w.Write([]byte(`{"reason": "Недостаточно прав для совершения действия"}`))
-	return w
+	return Error(w, http.StatusForbidden, "Недостаточно прав для совершения действия")
 }
 
 func WriteResponse(w http.ResponseWriter, code int, response []byte) http.ResponseWriter {
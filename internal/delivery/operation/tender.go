@@ -1,14 +1,17 @@
 package operation
 
-import "strconv"
+import (
+	"strconv"
+	"zadanie-6105/internal/repositories/entities"
+)
 
 type TenderListParams struct {
-	Limit       uint32
-	Offset      uint32
-	ServiceType []string
+	Limit  uint32
+	Offset uint32
+	entities.TenderFilter
 }
 
-func (res TenderListParams) Scan(limit string, offset string, service string) error {
+func (res *TenderListParams) Scan(limit string, offset string, service []string) error {
 	l, err := strconv.Atoi(limit)
 	if err != nil && limit != "" {
 		return err
@@ -19,5 +22,15 @@ func (res TenderListParams) Scan(limit string, offset string, service string) er
 		return err
 	}
 	res.Offset = uint32(o)
+	for _, s := range service {
+		res.ServiceType = append(res.ServiceType, entities.TenderType(s))
+	}
 	return nil
 }
+
+// ScanFilter читает остальные фильтры списка: слова поиска, диапазон бюджета
+// и ИНН организации.
+func (res *TenderListParams) ScanFilter(query string, budgetMin string, budgetMax string, inn string) error {
+	res.Query, res.BudgetMin, res.BudgetMax, res.INN = query, budgetMin, budgetMax, inn
+	return res.TenderFilter.Normalize()
+}
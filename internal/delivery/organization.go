@@ -0,0 +1,334 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type OrganizationHandler struct {
+	service services.Organization
+	logger  *zap.Logger
+}
+
+func NewOrganizationHandler(service services.Organization, logger *zap.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *OrganizationHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *OrganizationHandler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	username := params.Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	page := operation.BidParams{}
+	if err := page.Scan(params.Get("limit"), params.Get("offset")); err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	if page.Limit <= 0 {
+		page.Limit = 50
+	}
+	orgs, err := h.service.GetOrganizations(r.Context(), username, page.Limit, page.Offset)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(orgs)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["organizationId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	org, err := h.service.GetOrganization(r.Context(), id, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(org)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	h.save(w, r, "")
+}
+
+func (h *OrganizationHandler) EditOrganization(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["organizationId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	h.save(w, r, id)
+}
+
+// save создает организацию, если id пустой, иначе редактирует.
+func (h *OrganizationHandler) save(w http.ResponseWriter, r *http.Request, id string) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	org := entities.Organization{}
+	err = json.Unmarshal(body, &org)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	var res entities.Organization
+	if id == "" {
+		res, err = h.service.CreateOrganization(r.Context(), org, username)
+	} else {
+		res, err = h.service.EditOrganization(r.Context(), org, id, username)
+	}
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(res)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *OrganizationHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["organizationId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	if err := h.service.DeleteOrganization(r.Context(), id, username); err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) GetResponsibles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["organizationId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	responsibles, err := h.service.GetResponsibles(r.Context(), id, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(responsibles)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *OrganizationHandler) InviteResponsible(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["organizationId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	req := struct {
+		Username string                    `json:"username"`
+		Role     entities.OrganizationRole `json:"role"`
+	}{}
+	err = json.Unmarshal(body, &req)
+	if err != nil || req.Username == "" {
+		operation.BadRequest(w)
+		return
+	}
+	invitation, err := h.service.InviteResponsible(r.Context(), id, req.Username, req.Role, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(invitation)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *OrganizationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	req := struct {
+		Token string `json:"token"`
+	}{}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	org, err := h.service.AcceptInvitation(r.Context(), req.Token, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(org)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *OrganizationHandler) RemoveResponsible(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	vars := mux.Vars(r)
+	id, employeeId := vars["organizationId"], vars["employeeId"]
+	if id == "" || employeeId == "" {
+		operation.BadRequest(w)
+		return
+	}
+	if err := h.service.RemoveResponsible(r.Context(), id, employeeId, username); err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	vars := mux.Vars(r)
+	id, employeeId := vars["organizationId"], vars["employeeId"]
+	if id == "" || employeeId == "" {
+		operation.BadRequest(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	req := struct {
+		Role entities.OrganizationRole `json:"role"`
+	}{}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	if err := h.service.SetRole(r.Context(), id, employeeId, req.Role, username); err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package delivery
+
+import (
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/tracing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.uber.org/zap"
+)
+
+type Handlers struct {
+	Health       *HealthHandler
+	Tender       *TenderHandler
+	Bid          *BidHandler
+	Search       *SearchHandler
+	Organization *OrganizationHandler
+	Employee     *EmployeeHandler
+	Key          *KeyHandler
+	Webhook      *WebhookHandler
+	Stream       *StreamHandler
+	Notification *NotificationHandler
+	Mail         *MailHandler
+	Audit        *AuditHandler
+}
+
+// NewRouter регистрирует все маршруты сервиса. Его же поднимают тесты
+// pkg/client на httptest.
+func NewRouter(h Handlers, viewers middleware.ViewerResolver, logger *zap.Logger) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(
+		middleware.RequestID,
+		otelmux.Middleware(tracing.ServiceName),
+		middleware.Logging(logger),
+		middleware.Metrics,
+	)
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	router.HandleFunc("/healthz", h.Health.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", h.Health.Readyz).Methods("GET")
+
+	r := router.PathPrefix("/api").Subrouter()
+	r.Use(middleware.Viewer(viewers, logger))
+	r.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	r.HandleFunc("/tenders", h.Tender.GetTenderList).Methods("GET")
+	r.HandleFunc("/tenders/new", h.Tender.CreateTender).Methods("POST")
+	r.HandleFunc("/tenders/my", h.Tender.GetTenderByUser).Methods("GET")
+	r.HandleFunc("/tenders/watched", h.Search.GetWatched).Methods("GET")
+	r.HandleFunc("/tenders/{tenderId}/watch", h.Search.WatchTender).Methods("PUT", "DELETE")
+	r.HandleFunc("/tenders/{tenderId}/status", h.Tender.GetTenderStatus).Methods("GET")
+	r.HandleFunc("/tenders/{tenderId}/status", h.Tender.ChangeTenderStatus).Methods("PUT")
+	r.HandleFunc("/tenders/{tenderId}/edit", h.Tender.EditTender).Methods("PATCH")
+	r.HandleFunc("/searches", h.Search.GetSearches).Methods("GET")
+	r.HandleFunc("/searches", h.Search.CreateSearch).Methods("POST")
+	r.HandleFunc("/searches/{searchId}", h.Search.DeleteSearch).Methods("DELETE")
+	r.HandleFunc("/bids/new", h.Bid.CreateBid).Methods("POST")
+	r.HandleFunc("/bids/my", h.Bid.GetUserBids).Methods("GET")
+	r.HandleFunc("/bids/{tenderId}/my", h.Bid.GetBidsForTender).Methods("GET")
+	r.HandleFunc("/bids/{bidId}/status", h.Bid.GetBidStatus).Methods("GET")
+	r.HandleFunc("/bids/{bidId}/status", h.Bid.ChangeBidStatus).Methods("PUT")
+	r.HandleFunc("/bids/{bidId}/submit_decision", h.Bid.SubmitBid).Methods("PUT")
+	r.HandleFunc("/bids/{bidId}/edit", h.Bid.EditBid).Methods("PATCH")
+	r.HandleFunc("/bids/{bidId}/signatures", h.Bid.GetBidSignatures).Methods("GET")
+	r.HandleFunc("/bids/{bidId}/reveal", h.Bid.RevealBid).Methods("PUT")
+	r.HandleFunc("/organizations", h.Organization.GetOrganizations).Methods("GET")
+	r.HandleFunc("/organizations", h.Organization.CreateOrganization).Methods("POST")
+	r.HandleFunc("/organizations/{organizationId}", h.Organization.GetOrganization).Methods("GET")
+	r.HandleFunc("/organizations/{organizationId}", h.Organization.EditOrganization).Methods("PATCH")
+	r.HandleFunc("/organizations/{organizationId}", h.Organization.DeleteOrganization).Methods("DELETE")
+	r.HandleFunc("/organizations/{organizationId}/responsibles", h.Organization.GetResponsibles).Methods("GET")
+	r.HandleFunc("/organizations/{organizationId}/responsibles/{employeeId}", h.Organization.RemoveResponsible).Methods("DELETE")
+	r.HandleFunc("/organizations/{organizationId}/responsibles/{employeeId}", h.Organization.SetRole).Methods("PUT")
+	r.HandleFunc("/organizations/{organizationId}/invitations", h.Organization.InviteResponsible).Methods("POST")
+	r.HandleFunc("/invitations/accept", h.Organization.AcceptInvitation).Methods("POST")
+	r.HandleFunc("/employees", h.Employee.CreateEmployee).Methods("POST")
+	r.HandleFunc("/employees/keys", h.Key.GetKeys).Methods("GET")
+	r.HandleFunc("/employees/keys", h.Key.RegisterKey).Methods("POST")
+	r.HandleFunc("/employees/keys/{keyId}", h.Key.RevokeKey).Methods("DELETE")
+	r.HandleFunc("/employees/{employeeId}", h.Employee.EditEmployee).Methods("PATCH")
+	r.HandleFunc("/webhooks", h.Webhook.GetWebhooks).Methods("GET")
+	r.HandleFunc("/webhooks", h.Webhook.CreateWebhook).Methods("POST")
+	r.HandleFunc("/webhooks/{webhookId}", h.Webhook.DeleteWebhook).Methods("DELETE")
+	r.HandleFunc("/webhooks/{webhookId}/deliveries", h.Webhook.GetDeliveries).Methods("GET")
+	r.HandleFunc("/webhooks/deliveries/{deliveryId}/redeliver", h.Webhook.Redeliver).Methods("POST")
+	r.HandleFunc("/events/stream", h.Stream.Stream).Methods("GET")
+	r.HandleFunc("/notifications", h.Notification.GetNotifications).Methods("GET")
+	r.HandleFunc("/notifications/read_all", h.Notification.MarkAllRead).Methods("PUT")
+	r.HandleFunc("/notifications/preferences", h.Notification.GetPreferences).Methods("GET")
+	r.HandleFunc("/notifications/preferences", h.Notification.SetPreferences).Methods("PUT")
+	r.HandleFunc("/notifications/email", h.Mail.GetSettings).Methods("GET")
+	r.HandleFunc("/notifications/email", h.Mail.SetSettings).Methods("PUT")
+	r.HandleFunc("/notifications/{notificationId}/read", h.Notification.MarkRead).Methods("PUT")
+	r.HandleFunc("/audit", h.Audit.GetEntries).Methods("GET")
+	r.HandleFunc("/audit/verify", h.Audit.VerifyChain).Methods("GET")
+	r.HandleFunc("/audit/head", h.Audit.ChainHead).Methods("GET")
+	return router
+}
@@ -0,0 +1,163 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type SearchHandler struct {
+	service services.Search
+	logger  *zap.Logger
+}
+
+func NewSearchHandler(service services.Search, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *SearchHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *SearchHandler) GetSearches(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	searches, err := h.service.GetSearches(r.Context(), username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(searches)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *SearchHandler) CreateSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	search := entities.SavedSearch{}
+	err = json.Unmarshal(body, &search)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	res, err := h.service.CreateSearch(r.Context(), search, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(res)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *SearchHandler) DeleteSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["searchId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	if err := h.service.DeleteSearch(r.Context(), id, username); err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WatchTender — PUT подписывает на изменения тендера, DELETE отписывает.
+func (h *SearchHandler) WatchTender(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["tenderId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	watch := r.Method != http.MethodDelete
+	if err := h.service.WatchTender(r.Context(), id, watch, username); err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SearchHandler) GetWatched(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	username := params.Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	page := operation.BidParams{}
+	if err := page.Scan(params.Get("limit"), params.Get("offset")); err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	if page.Limit <= 0 {
+		page.Limit = 50
+	}
+	tenders, err := h.service.GetWatched(r.Context(), username, page.Limit, page.Offset)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(tenders)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
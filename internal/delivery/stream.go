@@ -0,0 +1,102 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/services"
+
+	"go.uber.org/zap"
+)
+
+// streamPing — комментарий-пинг, чтобы прокси не закрывали молчащее соединение.
+const streamPing = 15 * time.Second
+
+type StreamHandler struct {
+	service services.Stream
+	logger  *zap.Logger
+}
+
+func NewStreamHandler(service services.Stream, logger *zap.Logger) *StreamHandler {
+	return &StreamHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *StreamHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+// Stream отдает изменения тендера как Server-Sent Events. id события —
+// его номер в outbox; EventSource сам присылает его в Last-Event-ID при
+// переподключении, и пропущенное досылается.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	username := params.Get("username")
+	if username == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	tenderId := params.Get("tenderId")
+	if tenderId == "" {
+		operation.BadRequest(w)
+		return
+	}
+	var lastEventId int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			operation.BadRequest(w)
+			return
+		}
+		lastEventId = id
+	}
+	events, err := h.service.Subscribe(r.Context(), tenderId, lastEventId, username)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	rc := http.NewResponseController(w)
+	// поток живет дольше SERVER_WRITE_TIMEOUT
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.log(r).Warn("stream write deadline", zap.Error(err))
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		h.log(r).Error(err.Error())
+		return
+	}
+	ping := time.NewTicker(streamPing)
+	defer ping.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.log(r).Error(err.Error())
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -2,9 +2,9 @@ package delivery
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
 	"zadanie-6105/internal/delivery/operation"
 	"zadanie-6105/internal/repositories/entities"
 	"zadanie-6105/internal/services"
@@ -25,25 +25,32 @@ func NewTenderHandler(service services.Tender, logger *zap.Logger) *TenderHandle
 	}
 }
 
+func (h *TenderHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
 func (h *TenderHandler) GetTenderList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	params := r.URL.Query()
 	filterParams := operation.TenderListParams{}
-	err := filterParams.Scan(params.Get("limit"), params.Get("offset"), params.Get("service_type"))
+	err := filterParams.Scan(params.Get("limit"), params.Get("offset"), params["service_type"])
+	if err == nil {
+		err = filterParams.ScanFilter(params.Get("query"), params.Get("budget_min"), params.Get("budget_max"), params.Get("inn"))
+	}
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
-	tenders, err := h.service.GetTenderList(r.Context(), filterParams)
+	tenders, err := h.service.GetTenderList(r.Context(), filterParams, params.Get("username"))
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(tenders)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -54,7 +61,7 @@ func (h *TenderHandler) CreateTender(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -63,19 +70,19 @@ func (h *TenderHandler) CreateTender(w http.ResponseWriter, r *http.Request) {
 	t := entities.Tender{}
 	err = json.Unmarshal(body, &t)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
 	tender, err := h.service.CreateTender(r.Context(), t)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(tender)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -86,9 +93,12 @@ func (h *TenderHandler) GetTenderByUser(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	params := r.URL.Query()
 	filterParams := operation.TenderListParams{}
-	err := filterParams.Scan(params.Get("limit"), params.Get("offset"), "")
+	err := filterParams.Scan(params.Get("limit"), params.Get("offset"), params["service_type"])
+	if err == nil {
+		err = filterParams.ScanFilter(params.Get("query"), params.Get("budget_min"), params.Get("budget_max"), params.Get("inn"))
+	}
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -99,13 +109,13 @@ func (h *TenderHandler) GetTenderByUser(w http.ResponseWriter, r *http.Request)
 	}
 	tenders, err := h.service.GetTenderByUser(r.Context(), creator, filterParams)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(tenders)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -117,26 +127,26 @@ func (h *TenderHandler) GetTenderStatus(w http.ResponseWriter, r *http.Request)
 	params := r.URL.Query()
 	creator := params.Get("username")
 	if creator == "" {
-		h.logger.Error("no username")
+		h.log(r).Error("no username")
 		operation.Unauthorized(w)
 		return
 	}
 	vars := mux.Vars(r)
 	id := vars["tenderId"]
 	if id == "" {
-		h.logger.Error("no id")
+		h.log(r).Error("no id")
 		operation.BadRequest(w)
 		return
 	}
 	status, err := h.service.GetTenderStatus(r.Context(), id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(status)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -166,17 +176,13 @@ func (h *TenderHandler) ChangeTenderStatus(w http.ResponseWriter, r *http.Reques
 	}
 	tender, err := h.service.ChangeTenderStatus(r.Context(), status, id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		if errors.Is(services.ErrNotResponsible, err) || errors.Is(services.ErrNoAccess, err) {
-			operation.WriteResponse(w, 403, []byte(`{"reason": "`+err.Error()+`"}`))
-			return
-		}
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(tender)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -199,7 +205,7 @@ func (h *TenderHandler) EditTender(w http.ResponseWriter, r *http.Request) {
 	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
@@ -208,19 +214,19 @@ func (h *TenderHandler) EditTender(w http.ResponseWriter, r *http.Request) {
 	t := entities.Tender{}
 	err = json.Unmarshal(body, &t)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.BadRequest(w)
 		return
 	}
 	tender, err := h.service.EditTender(r.Context(), t, id, creator)
 	if err != nil {
-		h.logger.Error(err.Error())
-		operation.InternalServerError(w)
+		h.log(r).Error(err.Error())
+		writeError(w, err)
 		return
 	}
 	response, err := json.Marshal(tender)
 	if err != nil {
-		h.logger.Error(err.Error())
+		h.log(r).Error(err.Error())
 		operation.InternalServerError(w)
 		return
 	}
@@ -0,0 +1,179 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	service services.Webhook
+	logger  *zap.Logger
+}
+
+func NewWebhookHandler(service services.Webhook, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *WebhookHandler) log(r *http.Request) *zap.Logger {
+	return middleware.LoggerFromContext(r.Context(), h.logger)
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	creator := r.URL.Query().Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	defer r.Body.Close()
+
+	hook := entities.Webhook{}
+	err = json.Unmarshal(body, &hook)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	res, err := h.service.CreateWebhook(r.Context(), hook, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(res)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	organizationId := params.Get("organizationId")
+	if organizationId == "" {
+		operation.BadRequest(w)
+		return
+	}
+	hooks, err := h.service.GetWebhooks(r.Context(), organizationId, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(hooks)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	creator := r.URL.Query().Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["webhookId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	if err := h.service.DeleteWebhook(r.Context(), id, creator); err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	creator := params.Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["webhookId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	page := operation.BidParams{}
+	if err := page.Scan(params.Get("limit"), params.Get("offset")); err != nil {
+		h.log(r).Error(err.Error())
+		operation.BadRequest(w)
+		return
+	}
+	if page.Limit <= 0 {
+		page.Limit = 50
+	}
+	deliveries, err := h.service.GetDeliveries(r.Context(), id, page.Limit, page.Offset, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(deliveries)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	w.Write(response)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	creator := r.URL.Query().Get("username")
+	if creator == "" {
+		operation.Unauthorized(w)
+		return
+	}
+	id := mux.Vars(r)["deliveryId"]
+	if id == "" {
+		operation.BadRequest(w)
+		return
+	}
+	delivery, err := h.service.Redeliver(r.Context(), id, creator)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		writeError(w, err)
+		return
+	}
+	response, err := json.Marshal(delivery)
+	if err != nil {
+		h.log(r).Error(err.Error())
+		operation.InternalServerError(w)
+		return
+	}
+	operation.WriteResponse(w, http.StatusAccepted, response)
+}
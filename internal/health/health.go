@@ -0,0 +1,162 @@
+// Package health собирает состояние сервиса для проверок живости и готовности.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"zadanie-6105/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// staleFactor — во сколько интервалов воркер может молчать,
+// прежде чем считается зависшим.
+const staleFactor = 3
+
+type Check struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Details   string  `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type Checker struct {
+	db       *pgxpool.Pool
+	migrator *migrations.Migrator
+	expected int
+
+	mu       sync.Mutex
+	workers  map[string]*Heartbeat
+	stopping atomic.Bool
+}
+
+func NewChecker(db *pgxpool.Pool, migrator *migrations.Migrator, expectedVersion int) *Checker {
+	return &Checker{
+		db:       db,
+		migrator: migrator,
+		expected: expectedVersion,
+		workers:  map[string]*Heartbeat{},
+	}
+}
+
+// Heartbeat — отметки фонового воркера о том, что он жив.
+type Heartbeat struct {
+	interval time.Duration
+	last     atomic.Int64
+}
+
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// RegisterWorker регистрирует воркер, который вызывает Beat примерно раз в interval.
+func (c *Checker) RegisterWorker(name string, interval time.Duration) *Heartbeat {
+	h := &Heartbeat{interval: interval}
+	h.Beat()
+	c.mu.Lock()
+	c.workers[name] = h
+	c.mu.Unlock()
+	return h
+}
+
+// ShuttingDown переводит сервис в состояние остановки: готовность больше не проходит.
+func (c *Checker) ShuttingDown() {
+	c.stopping.Store(true)
+}
+
+// Liveness проверяет только, что процесс отвечает. Отметки воркеров в нее
+// не входят: долгий проход воркера не повод перезапускать под, и они
+// проверяются в Readiness.
+func (c *Checker) Liveness(ctx context.Context) Report {
+	return newReport(map[string]Check{"process": {Status: StatusOK}})
+}
+
+func (c *Checker) Readiness(ctx context.Context) Report {
+	checks := map[string]Check{}
+	checks["postgres"] = measure(func() (string, error) {
+		return "", c.db.Ping(ctx)
+	})
+	checks["migrations"] = measure(func() (string, error) {
+		version, err := c.migrator.Version(ctx)
+		if err != nil {
+			return "", err
+		}
+		details := fmt.Sprintf("version %d, expected %d", version, c.expected)
+		if version != c.expected {
+			return details, fmt.Errorf("schema version mismatch")
+		}
+		return details, nil
+	})
+	c.workerChecks(checks)
+	shutdown := Check{Status: StatusOK}
+	if c.stopping.Load() {
+		shutdown = Check{Status: StatusFail, Details: "shutting down"}
+	}
+	checks["shutdown"] = shutdown
+	return newReport(checks)
+}
+
+func (c *Checker) workerChecks(checks map[string]Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.workers))
+	for name := range c.workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h := c.workers[name]
+		since := time.Since(time.Unix(0, h.last.Load()))
+		check := Check{Status: StatusOK, Details: "last beat " + since.Round(time.Millisecond).String() + " ago"}
+		if since > staleFactor*h.interval {
+			check.Status = StatusFail
+		}
+		checks["worker:"+name] = check
+	}
+}
+
+func measure(fn func() (string, error)) Check {
+	start := time.Now()
+	details, err := fn()
+	check := Check{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		check.Status = StatusFail
+		if details != "" {
+			check.Details = details + ": " + err.Error()
+		} else {
+			check.Details = err.Error()
+		}
+	}
+	return check
+}
+
+func newReport(checks map[string]Check) Report {
+	report := Report{Status: StatusOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
@@ -0,0 +1,172 @@
+// Package mail отрисовывает письма по шаблонам и отправляет их через
+// подключаемый транспорт.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Transport отправляет письмо. Ошибка означает, что письмо не принято
+// и его нужно отправить повторно.
+type Transport interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogTransport только пишет письма в лог — для разработки.
+type LogTransport struct {
+	logger *zap.Logger
+}
+
+func NewLogTransport(logger *zap.Logger) *LogTransport {
+	return &LogTransport{logger: logger}
+}
+
+func (t *LogTransport) Send(ctx context.Context, msg Message) error {
+	t.logger.Info("mail", zap.String("to", msg.To), zap.String("subject", msg.Subject))
+	return nil
+}
+
+// SMTPTransport отправляет письма через SMTP-сервер. STARTTLS включается,
+// если сервер его поддерживает; без логина работает с локальным
+// перехватчиком вроде MailHog.
+type SMTPTransport struct {
+	addr     string
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func NewSMTPTransport(addr string, username string, password string, timeout time.Duration) *SMTPTransport {
+	return &SMTPTransport{
+		addr:     addr,
+		username: username,
+		password: password,
+		timeout:  timeout,
+	}
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg Message) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	host, _, err := net.SplitHostPort(t.addr)
+	if err != nil {
+		return err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if t.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", t.username, t.password, host)); err != nil {
+			return err
+		}
+	}
+	body, err := Encode(msg)
+	if err != nil {
+		return err
+	}
+	if err := c.Mail(msg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Encode собирает письмо multipart/alternative с текстовой и HTML-частью.
+func Encode(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", msg.From)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(msg.From))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+	buf.WriteString("\r\n")
+	parts := []struct{ typ, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.typ},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func messageID(from string) string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.Trim(from[i+1:], "> ")
+	}
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var files embed.FS
+
+const (
+	TemplateBidCreated      = "bid_created"
+	TemplateBidDecision     = "bid_decision"
+	TemplateTenderDeadline  = "tender_deadline"
+	TemplateTenderPublished = "tender_published"
+	TemplateDigest          = "digest"
+
+	DefaultLocale = "ru"
+)
+
+var (
+	locales   = []string{"ru", "en"}
+	templates = []string{TemplateBidCreated, TemplateBidDecision, TemplateTenderDeadline, TemplateTenderPublished, TemplateDigest}
+)
+
+// Data — данные шаблонов; каждый шаблон использует только свои поля.
+type Data struct {
+	FirstName string
+	Tender    string
+	Bid       string
+	Status    string
+	Deadline  time.Time
+	Items     []DigestItem
+}
+
+type DigestItem struct {
+	Title     string
+	CreatedAt time.Time
+}
+
+// Rendered — отрисованное письмо без адресов.
+type Rendered struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type pair struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer отрисовывает письма из templates/<locale>/<name>.txt и .html.
+// В .txt определены "subject" и "text", в .html — "html".
+type Renderer struct {
+	templates map[string]pair
+}
+
+// NewRenderer разбирает все шаблоны сразу, чтобы отсутствующий перевод
+// обнаружился при запуске, а не при первой отправке.
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{templates: map[string]pair{}}
+	for _, locale := range locales {
+		for _, name := range templates {
+			base := "templates/" + locale + "/" + name
+			text, err := texttemplate.ParseFS(files, base+".txt")
+			if err != nil {
+				return nil, err
+			}
+			html, err := htmltemplate.ParseFS(files, base+".html")
+			if err != nil {
+				return nil, err
+			}
+			r.templates[locale+"/"+name] = pair{text: text, html: html}
+		}
+	}
+	return r, nil
+}
+
+// Render отрисовывает шаблон name; неизвестная локаль заменяется DefaultLocale.
+func (r *Renderer) Render(locale string, name string, data Data) (Rendered, error) {
+	p, ok := r.templates[locale+"/"+name]
+	if !ok {
+		p, ok = r.templates[DefaultLocale+"/"+name]
+	}
+	if !ok {
+		return Rendered{}, fmt.Errorf("mail: нет шаблона %s", name)
+	}
+	var subject, text, html bytes.Buffer
+	if err := p.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Rendered{}, err
+	}
+	if err := p.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return Rendered{}, err
+	}
+	if err := p.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return Rendered{}, err
+	}
+	return Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
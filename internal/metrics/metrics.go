@@ -0,0 +1,63 @@
+// Package metrics содержит метрики Prometheus сервиса.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "tender"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Длительность обработки HTTP-запросов по шаблону маршрута.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	TendersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenders_created_total",
+		Help:      "Количество созданных тендеров.",
+	})
+	TendersPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenders_published_total",
+		Help:      "Количество опубликованных тендеров.",
+	})
+	BidsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bids_created_total",
+		Help:      "Количество созданных предложений.",
+	})
+	BidDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bid_decisions_total",
+		Help:      "Решения по предложениям по исходу.",
+	}, []string{"outcome"})
+	Awards = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "awards_total",
+		Help:      "Количество тендеров, закрытых выбором победителя.",
+	})
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Попытки доставки вебхуков по исходу: delivered, retry, failed.",
+	}, []string{"outcome"})
+	OutboxEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_total",
+		Help:      "Публикации событий из outbox по исходу: published, retry, failed.",
+	}, []string{"outcome"})
+	MailMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mail",
+		Name:      "messages_total",
+		Help:      "Попытки отправки писем по исходу: sent, retry, failed.",
+	}, []string{"outcome"})
+)
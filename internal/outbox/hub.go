@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// hubPoll — проверка новых событий без уведомления, например пока
+	// соединение LISTEN переподключается
+	hubPoll      = 5 * time.Second
+	hubReconnect = time.Second
+	hubBatch     = 500
+	// hubGapTimeout — сколько ждать пропущенный id: транзакция, получившая
+	// его раньше, может зафиксироваться после следующих. Дольше id считается
+	// откатившимся.
+	hubGapTimeout = time.Minute
+	hubMaxGaps    = 1000
+)
+
+// Hub раздает события из outbox подписчикам своего экземпляра сервиса.
+// Об изменениях на любом экземпляре он узнает через LISTEN/NOTIFY и читает
+// новые события из таблицы, поэтому не зависит от Dispatcher и его лидера.
+// Id выдаются до фиксации, поэтому событие с меньшим id может появиться
+// после больших: такие пропуски Hub перечитывает hubGapTimeout и раздает
+// найденное не по порядку id.
+type Hub struct {
+	repo   repositories.Outbox
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	subs   map[int]*hubSubscriber
+	nextID int
+	last   int64
+	// gaps — пропущенные id и время, когда пропуск замечен
+	gaps map[int64]time.Time
+}
+
+type hubSubscriber struct {
+	tenderId string
+	ch       chan entities.OutboxEntry
+}
+
+func NewHub(repo repositories.Outbox, logger *zap.Logger) *Hub {
+	return &Hub{
+		repo:   repo,
+		logger: logger.With(zap.String("component", "outbox_hub")),
+		subs:   map[int]*hubSubscriber{},
+		last:   -1,
+		gaps:   map[int64]time.Time{},
+	}
+}
+
+// Run слушает уведомления и раздает события до отмены ctx.
+func (h *Hub) Run(ctx context.Context) {
+	wake := make(chan struct{}, 1)
+	go h.listen(ctx, wake)
+	ticker := time.NewTicker(hubPoll)
+	defer ticker.Stop()
+	for {
+		if err := h.poll(ctx); err != nil && ctx.Err() == nil {
+			h.logger.Error("outbox poll failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			h.closeAll()
+			return
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Hub) listen(ctx context.Context, wake chan<- struct{}) {
+	for {
+		err := h.repo.Listen(ctx, wake)
+		if ctx.Err() != nil {
+			return
+		}
+		h.logger.Warn("outbox listen failed", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(hubReconnect):
+		}
+		// пока соединения не было, уведомления могли потеряться
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (h *Hub) poll(ctx context.Context) error {
+	if h.last < 0 {
+		last, err := h.repo.LastID(ctx)
+		if err != nil {
+			return err
+		}
+		h.last = last
+		return nil
+	}
+	now := time.Now()
+	for {
+		entries, err := h.repo.GetAfter(ctx, h.last, "", hubBatch)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			for id := h.last + 1; id < e.ID && len(h.gaps) < hubMaxGaps; id++ {
+				h.gaps[id] = now
+			}
+			h.fanOut(e)
+			h.last = e.ID
+		}
+		if len(entries) < hubBatch {
+			break
+		}
+	}
+	return h.pollGaps(ctx, now)
+}
+
+// pollGaps раздает события, зафиксированные после событий с большим id.
+func (h *Hub) pollGaps(ctx context.Context, now time.Time) error {
+	ids := make([]int64, 0, len(h.gaps))
+	for id, since := range h.gaps {
+		if now.Sub(since) > hubGapTimeout {
+			delete(h.gaps, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	entries, err := h.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		delete(h.gaps, e.ID)
+		h.fanOut(e)
+	}
+	return nil
+}
+
+// fanOut отдает событие подписчикам тендера. Подписчик с переполненным
+// каналом отключается: клиент переподключится с Last-Event-ID и дочитает
+// пропущенное из таблицы.
+func (h *Hub) fanOut(e entities.OutboxEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, sub := range h.subs {
+		if sub.tenderId != e.Event.TenderID {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(h.subs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, sub := range h.subs {
+		delete(h.subs, id)
+		close(sub.ch)
+	}
+}
+
+// Subscribe возвращает канал событий тендера и функцию отписки. Канал
+// закрывается при отписке, остановке Hub или переполнении.
+func (h *Hub) Subscribe(tenderId string, buffer int) (<-chan entities.OutboxEntry, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.nextID
+	h.nextID++
+	sub := &hubSubscriber{tenderId: tenderId, ch: make(chan entities.OutboxEntry, buffer)}
+	h.subs[id] = sub
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.subs[id] == sub {
+			delete(h.subs, id)
+			close(sub.ch)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+func (r *outboxRepo) LastID(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *outboxRepo) GetAfter(ctx context.Context, afterId int64, tenderId string, limit int) ([]entities.OutboxEntry, error) {
+	var res []entities.OutboxEntry
+	for _, e := range r.entries {
+		if e.ID > afterId && len(res) < limit {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (r *outboxRepo) GetByIDs(ctx context.Context, ids []int64) ([]entities.OutboxEntry, error) {
+	var res []entities.OutboxEntry
+	for _, e := range r.entries {
+		if slices.Contains(ids, e.ID) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func outboxEntry(id int64) entities.OutboxEntry {
+	return entities.OutboxEntry{ID: id, Event: entities.Event{TenderID: "t1"}}
+}
+
+func TestHubDeliversLateCommits(t *testing.T) {
+	repo := &outboxRepo{}
+	hub := NewHub(repo, zap.NewNop())
+	ch, cancel := hub.Subscribe("t1", 10)
+	defer cancel()
+	ctx := context.Background()
+	if err := hub.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// транзакция с id 2 еще не зафиксирована
+	repo.entries = []entities.OutboxEntry{outboxEntry(1), outboxEntry(3)}
+	if err := hub.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	repo.entries = []entities.OutboxEntry{outboxEntry(1), outboxEntry(2), outboxEntry(3)}
+	if err := hub.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for len(ch) > 0 {
+		got = append(got, (<-ch).ID)
+	}
+	if !slices.Equal(got, []int64{1, 3, 2}) {
+		t.Fatalf("got %v, want [1 3 2]", got)
+	}
+	if len(hub.gaps) != 0 {
+		t.Fatalf("gaps left: %v", hub.gaps)
+	}
+
+	// откатившийся id перестает перечитываться через hubGapTimeout
+	repo.entries = append(repo.entries, outboxEntry(5))
+	if err := hub.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	hub.gaps[4] = time.Now().Add(-2 * hubGapTimeout)
+	if err := hub.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(hub.gaps) != 0 {
+		t.Fatalf("gaps left: %v", hub.gaps)
+	}
+}
@@ -0,0 +1,125 @@
+// Package outbox разносит события из таблицы outbox по получателям.
+//
+// Сервисы пишут событие в той же транзакции, что и изменение, поэтому оно
+// не теряется при падении между коммитом и отправкой. Dispatcher читает
+// очередь по порядку id и отдает каждое событие всем Sink; событие помечается
+// опубликованным, только когда его приняли все. Принявшие получатели
+// запоминаются, и после сбоя событие повторяется только для остальных.
+// Доставка — не менее одного раза: если сервис упадет между приемом и
+// отметкой, получатель увидит событие снова, поэтому дубли отбрасываются по
+// Event.ID.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"zadanie-6105/internal/metrics"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+const (
+	batchSize = 100
+	// maxAttempts — после стольких неудач событие уходит в failed_at и
+	// больше не задерживает остальные события сущности.
+	maxAttempts = 10
+)
+
+// Sink — получатель событий. Publish должен вернуть ошибку, если событие
+// не принято: тогда оно будет отправлено повторно.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event entities.Event) error
+}
+
+type Dispatcher struct {
+	repo   repositories.Outbox
+	sinks  []Sink
+	logger *zap.Logger
+}
+
+func NewDispatcher(repo repositories.Outbox, sinks []Sink, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		sinks:  sinks,
+		logger: logger,
+	}
+}
+
+// Dispatch публикует накопившиеся события и возвращает число опубликованных.
+// Если событие сущности не удалось опубликовать, следующие события той же
+// сущности ждут следующего прохода — так сохраняется порядок внутри нее.
+// Вызывается фоновым воркером; одновременно работает только один экземпляр.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	release, ok, err := d.repo.TryLock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	defer release()
+	published := 0
+	for {
+		entries, err := d.repo.GetPending(ctx, batchSize)
+		if err != nil {
+			return published, err
+		}
+		blocked := map[string]bool{}
+		for _, e := range entries {
+			typ, id := e.Event.Aggregate()
+			key := typ + ":" + id
+			if blocked[key] {
+				continue
+			}
+			if err := d.publish(ctx, e); err != nil {
+				blocked[key] = true
+				final := e.Attempts+1 >= maxAttempts
+				d.logger.Warn("outbox publish failed",
+					zap.Int64("outbox_id", e.ID),
+					zap.String("event_id", e.Event.ID),
+					zap.String("event_type", string(e.Event.Type)),
+					zap.Int("attempt", e.Attempts+1),
+					zap.Bool("final", final),
+					zap.Error(err))
+				if final {
+					metrics.OutboxEvents.WithLabelValues("failed").Inc()
+				} else {
+					metrics.OutboxEvents.WithLabelValues("retry").Inc()
+				}
+				if err := d.repo.MarkFailed(ctx, e.ID, err.Error(), final); err != nil {
+					return published, err
+				}
+				continue
+			}
+			metrics.OutboxEvents.WithLabelValues("published").Inc()
+			if err := d.repo.MarkPublished(ctx, e.ID); err != nil {
+				return published, err
+			}
+			published++
+		}
+		// неполная пачка или сбой — остальное на следующем проходе
+		if len(entries) < batchSize || len(blocked) > 0 {
+			return published, nil
+		}
+	}
+}
+
+// publish отдает событие получателям, которые еще не приняли его.
+func (d *Dispatcher) publish(ctx context.Context, e entities.OutboxEntry) error {
+	for _, sink := range d.sinks {
+		if slices.Contains(e.Sinks, sink.Name()) {
+			continue
+		}
+		if err := sink.Publish(ctx, e.Event); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+		if err := d.repo.MarkSinkPublished(ctx, e.ID, sink.Name()); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+
+	"go.uber.org/zap"
+)
+
+// outboxRepo держит очередь в памяти и, как таблица outbox_sink, помнит
+// принявших событие получателей.
+type outboxRepo struct {
+	repositories.Outbox
+	entries   []entities.OutboxEntry
+	published map[int64]bool
+}
+
+func (r *outboxRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+func (r *outboxRepo) GetPending(ctx context.Context, limit int) ([]entities.OutboxEntry, error) {
+	var res []entities.OutboxEntry
+	for _, e := range r.entries {
+		if !r.published[e.ID] {
+			e.Sinks = slices.Clone(e.Sinks)
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (r *outboxRepo) entry(id int64) *entities.OutboxEntry {
+	for i := range r.entries {
+		if r.entries[i].ID == id {
+			return &r.entries[i]
+		}
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkSinkPublished(ctx context.Context, id int64, sink string) error {
+	e := r.entry(id)
+	e.Sinks = append(e.Sinks, sink)
+	return nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, id int64) error {
+	r.published[id] = true
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, reason string, final bool) error {
+	r.entry(id).Attempts++
+	return nil
+}
+
+// countingSink считает принятые события и отказывает первые fail раз.
+type countingSink struct {
+	name     string
+	fail     int
+	accepted map[string]int
+}
+
+func (s *countingSink) Name() string { return s.name }
+
+func (s *countingSink) Publish(ctx context.Context, event entities.Event) error {
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("недоступен")
+	}
+	s.accepted[event.ID]++
+	return nil
+}
+
+func TestDispatchRetriesOnlyFailedSinks(t *testing.T) {
+	repo := &outboxRepo{
+		entries: []entities.OutboxEntry{
+			{ID: 1, Event: entities.Event{ID: "e1", Type: entities.EventTenderCreated, TenderID: "t1"}},
+		},
+		published: map[int64]bool{},
+	}
+	first := &countingSink{name: "first", accepted: map[string]int{}}
+	flaky := &countingSink{name: "flaky", fail: 1, accepted: map[string]int{}}
+	d := NewDispatcher(repo, []Sink{first, flaky}, zap.NewNop())
+
+	n, err := d.Dispatch(context.Background())
+	if err != nil || n != 0 {
+		t.Fatalf("first pass: published %d, err %v", n, err)
+	}
+	n, err = d.Dispatch(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("second pass: published %d, err %v", n, err)
+	}
+	if first.accepted["e1"] != 1 || flaky.accepted["e1"] != 1 {
+		t.Fatalf("accepted first=%d flaky=%d, want 1 each", first.accepted["e1"], flaky.accepted["e1"])
+	}
+	if !repo.published[1] {
+		t.Fatal("event is not marked published")
+	}
+}
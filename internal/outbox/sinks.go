@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// LogSink пишет события в лог.
+type LogSink struct {
+	logger *zap.Logger
+}
+
+func NewLogSink(logger *zap.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string { return "log" }
+
+func (s *LogSink) Publish(ctx context.Context, event entities.Event) error {
+	s.logger.Info("event",
+		zap.String("event_id", event.ID),
+		zap.String("event_type", string(event.Type)),
+		zap.String("organization_id", event.OrganizationID),
+		zap.String("tender_id", event.TenderID),
+		zap.String("bid_id", event.BidID))
+	return nil
+}
+
+// EventsSink передает события services.Events, например очереди вебхуков.
+type EventsSink struct {
+	name   string
+	events services.Events
+}
+
+func NewEventsSink(name string, events services.Events) *EventsSink {
+	return &EventsSink{name: name, events: events}
+}
+
+func (s *EventsSink) Name() string { return s.name }
+
+func (s *EventsSink) Publish(ctx context.Context, event entities.Event) error {
+	return s.events.Publish(ctx, event)
+}
+
+// Bus раздает события подписчикам внутри процесса. Подписчик, не успевающий
+// разбирать канал, пропускает события, чтобы не задерживать остальных.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[int]chan entities.Event
+	nextID int
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[int]chan entities.Event{}}
+}
+
+func (b *Bus) Name() string { return "bus" }
+
+func (b *Bus) Publish(ctx context.Context, event entities.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe возвращает канал событий и функцию отписки, закрывающую канал.
+func (b *Bus) Subscribe(buffer int) (<-chan entities.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	ch := make(chan entities.Event, buffer)
+	b.subs[id] = ch
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs, id)
+			close(ch)
+		})
+	}
+}
+
+// NATSSink публикует событие в subject <prefix>.<type>. Заголовок Nats-Msg-Id
+// позволяет JetStream отбросить повторную отправку.
+type NATSSink struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+func NewNATSSink(url string, prefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("tender-outbox"))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSSink{conn: conn, prefix: prefix}, nil
+}
+
+func (s *NATSSink) Name() string { return "nats" }
+
+func (s *NATSSink) Publish(ctx context.Context, event entities.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(s.prefix + "." + string(event.Type))
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Data = data
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	// без FlushWithContext событие могло остаться в буфере клиента и
+	// потеряться, хотя outbox уже отметил бы его опубликованным
+	return s.conn.FlushWithContext(ctx)
+}
+
+func (s *NATSSink) Close() {
+	s.conn.Close()
+}
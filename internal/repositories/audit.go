@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Audit interface {
+	Create(ctx context.Context, entry entities.AuditEntry) error
+	GetByEntity(ctx context.Context, entity entities.AuditEntity, id string) (entities.AuditList, error)
+	GetChain(ctx context.Context, tenderId string) (entities.AuditList, error)
+}
+
+type AuditRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepo(db *pgxpool.Pool) Audit {
+	return &AuditRepo{db: db}
+}
+
+// Create добавляет запись в конец цепочки тендера; seq, prev_hash и hash
+// вычисляет триггер audit_log_chain.
+func (t *AuditRepo) Create(ctx context.Context, entry entities.AuditEntry) error {
+	query := `
+		insert into audit_log(tender_id, actor_id, organization_id, entity_type, entity_id, action, diff, reason, request_id)
+		values ($1, nullif($2, '')::uuid, nullif($3, '')::uuid, $4, $5, $6, $7, nullif($8, ''), nullif($9, ''))
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query,
+		entry.TenderID,
+		entry.ActorID,
+		entry.OrganizationID,
+		entry.EntityType,
+		entry.EntityID,
+		entry.Action,
+		entry.Diff,
+		entry.Reason,
+		entry.RequestID,
+	)
+	return err
+}
+
+// diff читается как текст, чтобы получить ровно то представление jsonb,
+// от которого считался хеш.
+const auditColumns = `
+	id, tender_id, seq, coalesce(actor_id::text, ''), coalesce(organization_id::text, ''), entity_type, entity_id,
+	action, diff::text, coalesce(reason, ''), coalesce(request_id, ''), created_at, coalesce(prev_hash, ''), hash
+`
+
+func (t *AuditRepo) GetByEntity(ctx context.Context, entity entities.AuditEntity, id string) (entities.AuditList, error) {
+	query := `select ` + auditColumns + ` from audit_log where entity_type=$1 and entity_id=$2 order by id`
+	return t.list(ctx, query, entity, id)
+}
+
+func (t *AuditRepo) GetChain(ctx context.Context, tenderId string) (entities.AuditList, error) {
+	query := `select ` + auditColumns + ` from audit_log where tender_id=$1 order by seq`
+	return t.list(ctx, query, tenderId)
+}
+
+func (t *AuditRepo) list(ctx context.Context, query string, args ...any) (entities.AuditList, error) {
+	var res entities.AuditList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			e, err := scanAuditEntry(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.AuditList{}, err
+	}
+	return res, nil
+}
+
+func scanAuditEntry(rows pgx.Rows) (entities.AuditEntry, error) {
+	var (
+		e    entities.AuditEntry
+		diff string
+	)
+	err := rows.Scan(&e.ID, &e.TenderID, &e.Seq, &e.ActorID, &e.OrganizationID, &e.EntityType, &e.EntityID,
+		&e.Action, &diff, &e.Reason, &e.RequestID, &e.CreatedAt, &e.PrevHash, &e.Hash)
+	e.Diff = []byte(diff)
+	return e, err
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"zadanie-6105/internal/delivery/operation"
 	"zadanie-6105/internal/repositories/entities"
@@ -10,14 +11,22 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrBidNotFound — предложения нет или политики RLS скрывают его от сотрудника.
+var ErrBidNotFound = errors.New("предложение не найдено")
+
 type Bid interface {
 	Create(ctx context.Context, bid entities.Bid) (entities.Bid, error)
-	GetByTender(ctx context.Context, tender_id string, params operation.BidParams) (entities.BidList, error)
+	GetByTender(ctx context.Context, tender_id string, params operation.BidParams, viewer entities.Viewer) (entities.BidList, error)
 	GetUserBids(ctx context.Context, params operation.BidParams, id string) (entities.BidList, error)
 	GetBidStatus(ctx context.Context, id string) (entities.BidStatus, error)
 	ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string) (entities.Bid, error)
 	EditBid(ctx context.Context, bid entities.Bid, id string) (entities.Bid, error)
 	GetTenderIDForBid(ctx context.Context, id string) (string, error)
+	GetByID(ctx context.Context, id string) (entities.Bid, error)
+	Lock(ctx context.Context, id string) error
+	Reveal(ctx context.Context, id string, price string, salt string) (entities.Bid, error)
+	GetUnrevealed(ctx context.Context) (entities.BidList, error)
+	CountByTender(ctx context.Context, tenderId string) (int, error)
 }
 
 type BidRepo struct {
@@ -30,12 +39,13 @@ func NewBidRepo(db *pgxpool.Pool) Bid {
 
 func (t *BidRepo) Create(ctx context.Context, bid entities.Bid) (entities.Bid, error) {
 	query := `
-		insert into bid(name, description, status, tender_id, author_id, author_type, version, created_at) 
-		values ($1, $2, $3, $4, $5, $6, 1, now()) 
-		returning id, name, status, author_type, author_id, version, created_at
+		insert into bid(name, description, status, tender_id, author_id, author_type, version, created_at, commitment)
+		values ($1, $2, $3, $4, $5, $6, 1, now(), nullif($7, ''))
+		returning id, name, status, author_type, author_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at
 	`
 	var res entities.Bid
-	row := t.db.QueryRow(
+	row := conn(ctx, t.db).QueryRow(
 		ctx, query,
 		bid.Name,
 		bid.Description,
@@ -43,6 +53,7 @@ func (t *BidRepo) Create(ctx context.Context, bid entities.Bid) (entities.Bid, e
 		bid.TenderID,
 		bid.AuthorID,
 		bid.AuthorType,
+		bid.Commitment,
 	)
 	err := row.Scan(
 		&res.ID,
@@ -51,40 +62,55 @@ func (t *BidRepo) Create(ctx context.Context, bid entities.Bid) (entities.Bid, e
 		&res.AuthorType,
 		&res.AuthorID,
 		&res.Version,
-		&res.CreatedAt)
+		&res.CreatedAt,
+		&res.Commitment,
+		&res.Price,
+		&res.Salt,
+		&res.RevealedAt)
 	if err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
-func (t *BidRepo) GetByTender(ctx context.Context, tender_id string, params operation.BidParams) (entities.BidList, error) {
+func (t *BidRepo) GetByTender(ctx context.Context, tender_id string, params operation.BidParams,
+	viewer entities.Viewer) (entities.BidList, error) {
 	var (
 		res entities.BidList
 		sb  strings.Builder
 	)
 	sb.WriteString(
-		`select id, name, status, author_type, author_id, version, created_at from bid 
-		where tender_id=@id`)
+		`select id, name, status, author_type, author_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at
+		from bid where tender_id=@id and ` + bidVisibleSQL("bid"))
 	queryFilters, args := t.inQuery(params)
 	sb.WriteString(queryFilters)
-	namedArgs := pgx.NamedArgs{
+	namedArgs := viewerArgs(pgx.NamedArgs{
 		"limit":  args["limit"],
 		"offset": args["offset"],
 		"id":     tender_id,
-	}
-	rows, err := t.db.Query(ctx, sb.String(), namedArgs)
-	if err != nil {
-		return entities.BidList{}, err
-	}
-	for rows.Next() {
-		var bid entities.Bid
-		err := rows.Scan(&bid.ID, &bid.Name, &bid.Status, &bid.AuthorType,
-			&bid.AuthorID, &bid.Version, &bid.CreatedAt)
+	}, viewer)
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, sb.String(), namedArgs)
 		if err != nil {
-			return entities.BidList{}, err
+			return err
 		}
-		res = append(res, bid)
+		defer rows.Close()
+		for rows.Next() {
+			var bid entities.Bid
+			err := rows.Scan(&bid.ID, &bid.Name, &bid.Status, &bid.AuthorType,
+				&bid.AuthorID, &bid.Version, &bid.CreatedAt,
+				&bid.Commitment, &bid.Price, &bid.Salt, &bid.RevealedAt)
+			if err != nil {
+				return err
+			}
+			res = append(res, bid)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.BidList{}, err
 	}
 	return res, nil
 }
@@ -95,7 +121,8 @@ func (t *BidRepo) GetUserBids(ctx context.Context, params operation.BidParams, i
 		sb  strings.Builder
 	)
 	sb.WriteString(
-		`select id, name, status, author_type, author_id, version, created_at
+		`select id, name, status, author_type, author_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at
 		from bid where author_id=@id order by name ASC`)
 	queryFilters, args := t.inQuery(params)
 	sb.WriteString(queryFilters)
@@ -104,18 +131,27 @@ func (t *BidRepo) GetUserBids(ctx context.Context, params operation.BidParams, i
 		"offset": args["offset"],
 		"id":     id,
 	}
-	rows, err := t.db.Query(ctx, sb.String(), namedArgs)
-	if err != nil {
-		return entities.BidList{}, err
-	}
-	for rows.Next() {
-		var bid entities.Bid
-		err := rows.Scan(&bid.ID, &bid.Name, &bid.Status,
-			&bid.AuthorType, &bid.AuthorID, &bid.Version, &bid.CreatedAt)
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, sb.String(), namedArgs)
 		if err != nil {
-			return entities.BidList{}, err
+			return err
 		}
-		res = append(res, bid)
+		defer rows.Close()
+		for rows.Next() {
+			var bid entities.Bid
+			err := rows.Scan(&bid.ID, &bid.Name, &bid.Status,
+				&bid.AuthorType, &bid.AuthorID, &bid.Version, &bid.CreatedAt,
+				&bid.Commitment, &bid.Price, &bid.Salt, &bid.RevealedAt)
+			if err != nil {
+				return err
+			}
+			res = append(res, bid)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.BidList{}, err
 	}
 	return res, nil
 }
@@ -123,12 +159,14 @@ func (t *BidRepo) GetUserBids(ctx context.Context, params operation.BidParams, i
 func (t *BidRepo) ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string) (entities.Bid, error) {
 	query := `
 		update bid set status=$1, updated_at=now() where id=$2
-		returning id, name, status, author_type, author_id, version, created_at
+		returning id, name, status, author_type, author_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at
 	`
 	var res entities.Bid
-	row := t.db.QueryRow(ctx, query, status, id)
+	row := conn(ctx, t.db).QueryRow(ctx, query, status, id)
 	err := row.Scan(&res.ID, &res.Name, &res.Status,
-		&res.AuthorType, &res.AuthorID, &res.Version, &res.CreatedAt)
+		&res.AuthorType, &res.AuthorID, &res.Version, &res.CreatedAt,
+		&res.Commitment, &res.Price, &res.Salt, &res.RevealedAt)
 	if err != nil {
 		return res, err
 	}
@@ -138,7 +176,24 @@ func (t *BidRepo) ChangeBidStatus(ctx context.Context, status entities.BidStatus
 func (t *BidRepo) GetBidStatus(ctx context.Context, id string) (entities.BidStatus, error) {
 	query := `select status from bid where id=$1`
 	var res entities.BidStatus
-	err := t.db.QueryRow(ctx, query, id).Scan(&res)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res)
+	})
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// CountByTender считает все предложения тендера, включая скрытые от
+// сотрудника: их число публично.
+func (t *BidRepo) CountByTender(ctx context.Context, tenderId string) (int, error) {
+	query := `select count(*) from bid where tender_id=$1`
+	var res int
+	ctx = System(ctx)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, tenderId).Scan(&res)
+	})
 	if err != nil {
 		return res, err
 	}
@@ -148,13 +203,51 @@ func (t *BidRepo) GetBidStatus(ctx context.Context, id string) (entities.BidStat
 func (t *BidRepo) GetTenderIDForBid(ctx context.Context, id string) (string, error) {
 	query := `select tender_id from bid where id=$1`
 	var res string
-	err := t.db.QueryRow(ctx, query, id).Scan(&res)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, ErrBidNotFound
+	}
 	if err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
+// Lock блокирует предложение до конца транзакции, чтобы раскрытие и смена
+// статуса не шли параллельно.
+func (t *BidRepo) Lock(ctx context.Context, id string) error {
+	query := `select id from bid where id=$1 for update`
+	err := conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrBidNotFound
+	}
+	return err
+}
+
+func (t *BidRepo) GetByID(ctx context.Context, id string) (entities.Bid, error) {
+	query := `
+		select id, name, coalesce(description, ''), status, author_type, author_id,
+		tender_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at
+		from bid where id=$1
+	`
+	var res entities.Bid
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res.ID, &res.Name, &res.Description, &res.Status,
+			&res.AuthorType, &res.AuthorID, &res.TenderID, &res.Version, &res.CreatedAt,
+			&res.Commitment, &res.Price, &res.Salt, &res.RevealedAt)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Bid{}, ErrBidNotFound
+	}
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	return res, nil
+}
+
 func (t *BidRepo) inQuery(params operation.BidParams) (string, map[string]any) {
 	var (
 		args map[string]any = map[string]any{
@@ -183,15 +276,18 @@ func (t *BidRepo) EditBid(ctx context.Context, bid entities.Bid, id string) (ent
 		`update bid set version=version+1`)
 	queryFilters, args := t.EditQuery(bid)
 	sb.WriteString(queryFilters)
-	sb.WriteString(` where id=@id returning id, name, status, author_type, author_id, version, created_at`)
+	sb.WriteString(` where id=@id returning id, name, status, author_type, author_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at`)
 	namedArgs := pgx.NamedArgs{
 		"name":        args["name"],
 		"description": args["description"],
+		"commitment":  args["commitment"],
 		"id":          id,
 	}
-	row := t.db.QueryRow(ctx, sb.String(), namedArgs)
+	row := conn(ctx, t.db).QueryRow(ctx, sb.String(), namedArgs)
 	err := row.Scan(&res.ID, &res.Name, &res.Status,
-		&res.AuthorType, &res.AuthorID, &res.Version, &res.CreatedAt)
+		&res.AuthorType, &res.AuthorID, &res.Version, &res.CreatedAt,
+		&res.Commitment, &res.Price, &res.Salt, &res.RevealedAt)
 	if err != nil {
 		return entities.Bid{}, err
 	}
@@ -209,5 +305,66 @@ func (t *BidRepo) EditQuery(params entities.Bid) (string, map[string]any) {
 		sb.WriteString(`, description=@description`)
 		args["description"] = params.Description
 	}
+	if params.Commitment != "" {
+		sb.WriteString(`, commitment=@commitment`)
+		args["commitment"] = params.Commitment
+	}
 	return sb.String(), args
 }
+
+// Reveal сохраняет раскрытые цену и соль предложения закрытого тендера.
+func (t *BidRepo) Reveal(ctx context.Context, id string, price string, salt string) (entities.Bid, error) {
+	query := `
+		update bid set price=$2::numeric, salt=$3, revealed_at=now(), updated_at=now()
+		where id=$1 and revealed_at is null
+		returning id, name, status, author_type, author_id, version, created_at,
+		coalesce(commitment, ''), coalesce(price::text, ''), coalesce(salt, ''), revealed_at
+	`
+	var res entities.Bid
+	row := conn(ctx, t.db).QueryRow(ctx, query, id, price, salt)
+	err := row.Scan(&res.ID, &res.Name, &res.Status,
+		&res.AuthorType, &res.AuthorID, &res.Version, &res.CreatedAt,
+		&res.Commitment, &res.Price, &res.Salt, &res.RevealedAt)
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	return res, nil
+}
+
+// GetUnrevealed возвращает предложения закрытых тендеров, которые не были
+// раскрыты до окончания срока и еще не дисквалифицированы.
+func (t *BidRepo) GetUnrevealed(ctx context.Context) (entities.BidList, error) {
+	query := `
+		select b.id, b.name, coalesce(b.description, ''), b.status, b.author_type, b.author_id,
+		b.tender_id, b.version, b.created_at,
+		coalesce(b.commitment, ''), coalesce(b.price::text, ''), coalesce(b.salt, ''), b.revealed_at
+		from bid b join tender t on t.id=b.tender_id
+		where t.sealed and t.reveal_deadline <= now() and b.revealed_at is null
+		and b.status not in ('disqualified', 'canceled')
+		order by t.reveal_deadline
+	`
+	var res entities.BidList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var bid entities.Bid
+			err := rows.Scan(&bid.ID, &bid.Name, &bid.Description, &bid.Status, &bid.AuthorType,
+				&bid.AuthorID, &bid.TenderID, &bid.Version, &bid.CreatedAt,
+				&bid.Commitment, &bid.Price, &bid.Salt, &bid.RevealedAt)
+			if err != nil {
+				return err
+			}
+			res = append(res, bid)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.BidList{}, err
+	}
+	return res, nil
+}
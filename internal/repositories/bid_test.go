@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// testPool подключается к базе из TEST_POSTGRES_CONN и применяет миграции.
+// Без переменной тест пропускается: в базе создаются и удаляются строки,
+// поэтому рабочую базу указывать нельзя.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	conn := os.Getenv("TEST_POSTGRES_CONN")
+	if conn == "" {
+		t.Skip("TEST_POSTGRES_CONN не задана")
+	}
+	ctx := context.Background()
+	cfg := config.Postgres{Conn: conn, MaxConns: 4, ConnectTimeout: 5 * time.Second}
+	migrationDB, err := NewMigrationPool(ctx, cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer migrationDB.Close()
+	m, err := migrations.New(migrationDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewPool(ctx, cfg, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+// testTender создает сотрудника, его организацию и тендер закрытого типа,
+// возвращает id сотрудника и тендера.
+func testTender(t *testing.T, db *pgxpool.Pool) (string, string) {
+	t.Helper()
+	ctx := System(context.Background())
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	var employeeId, organizationId, tenderId string
+	err := db.QueryRow(ctx, `insert into employee(username) values ($1) returning id`, "test_"+suffix).Scan(&employeeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec(ctx, `delete from employee where id = $1`, employeeId) })
+	err = db.QueryRow(ctx, `insert into organization(name, type) values ($1, 'LLC') returning id`, "test_"+suffix).Scan(&organizationId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec(ctx, `delete from organization where id = $1`, organizationId) })
+	_, err = db.Exec(ctx, `insert into organization_responsible(organization_id, user_id) values ($1, $2)`, organizationId, employeeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow(ctx, `
+		insert into tender(name, service_type, status, organization_id, creator_username, version,
+			sealed, bid_deadline, reveal_deadline)
+		values ($1, 'delivery', 'published', $2, $3, 1, true, now() + interval '1 day', now() + interval '2 days')
+		returning id`, "test_"+suffix, organizationId, "test_"+suffix).Scan(&tenderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return employeeId, tenderId
+}
+
+func TestBidRepoCreate(t *testing.T) {
+	db := testPool(t)
+	authorId, tenderId := testTender(t, db)
+	repo := NewBidRepo(db)
+	ctx := WithViewer(context.Background(), entities.Viewer{ID: authorId})
+
+	commitment := entities.BidCommitment("1500.00", "соль-не-короче-16")
+	created, err := repo.Create(ctx, entities.Bid{
+		Name:       "test_bid_" + tenderId,
+		TenderID:   tenderId,
+		AuthorID:   authorId,
+		AuthorType: "user",
+		Commitment: commitment,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.Status != entities.BidStatusCreated || created.Version != 1 {
+		t.Fatalf("unexpected bid %+v", created)
+	}
+	if created.AuthorID != authorId || created.Commitment != commitment || created.RevealedAt != nil {
+		t.Fatalf("unexpected bid %+v", created)
+	}
+
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != created.ID || got.Commitment != commitment || got.Version != created.Version {
+		t.Fatalf("GetByID = %+v, want %+v", got, created)
+	}
+}
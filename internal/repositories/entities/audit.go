@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntity string
+type AuditAction string
+
+var (
+	AuditEntityTender AuditEntity = "tender"
+	AuditEntityBid    AuditEntity = "bid"
+
+	AuditActionCreate       AuditAction = "create"
+	AuditActionEdit         AuditAction = "edit"
+	AuditActionStatusChange AuditAction = "status_change"
+	AuditActionDecision     AuditAction = "decision"
+	// изменение статуса администратором в обход проверок прав, всегда с причиной
+	AuditActionForcedStatusChange AuditAction = "forced_status_change"
+	AuditActionReveal             AuditAction = "reveal"
+	AuditActionDisqualify         AuditAction = "disqualify"
+)
+
+func (e *AuditEntity) Scan(str string) {
+	switch str {
+	case "tender":
+		*e = AuditEntityTender
+	case "bid":
+		*e = AuditEntityBid
+	default:
+		*e = ""
+	}
+}
+
+type AuditEntry struct {
+	ID int64 `json:"id"`
+	// записи тендера и его предложений образуют одну цепочку
+	TenderID       string          `json:"tender_id"`
+	Seq            int64           `json:"seq"`
+	ActorID        string          `json:"actor_id,omitempty"`
+	OrganizationID string          `json:"organization_id,omitempty"`
+	EntityType     AuditEntity     `json:"entity_type"`
+	EntityID       string          `json:"entity_id"`
+	Action         AuditAction     `json:"action"`
+	Diff           json.RawMessage `json:"diff"`
+	Reason         string          `json:"reason,omitempty"`
+	RequestID      string          `json:"request_id,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	PrevHash       string          `json:"prev_hash,omitempty"`
+	Hash           string          `json:"hash"`
+}
+
+type AuditList []AuditEntry
+
+// ChainBreak описывает первое нарушенное звено цепочки.
+type ChainBreak struct {
+	ID     int64  `json:"id"`
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
+
+type ChainReport struct {
+	TenderID string      `json:"tender_id"`
+	Entries  int         `json:"entries"`
+	Valid    bool        `json:"valid"`
+	Head     string      `json:"head,omitempty"`
+	BrokenAt *ChainBreak `json:"broken_at,omitempty"`
+}
+
+// ChainHead — подписанная голова цепочки для проверки истории без доступа к сервису.
+// Подписывается строка Message.
+type ChainHead struct {
+	TenderID  string    `json:"tender_id"`
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	SignedAt  time.Time `json:"signed_at"`
+	Message   string    `json:"message"`
+	Algorithm string    `json:"algorithm"`
+	PublicKey string    `json:"public_key"`
+	Signature string    `json:"signature"`
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 type BidStatus string
 
@@ -10,6 +14,8 @@ var (
 	BidStatusPublished BidStatus = "published"
 	BidStatusApproved  BidStatus = "approved"
 	BidStatusRejected  BidStatus = "rejected"
+	// предложение закрытого тендера не раскрыто вовремя или не совпало с обязательством
+	BidStatusDisqualified BidStatus = "disqualified"
 )
 
 func (status *BidStatus) Scan(str string) {
@@ -24,11 +30,26 @@ func (status *BidStatus) Scan(str string) {
 		*status = BidStatusApproved
 	case "rejected":
 		*status = BidStatusRejected
+	case "disqualified":
+		*status = BidStatusDisqualified
 	default:
 		*status = ""
 	}
 }
 
+// SubmittedBidStatuses — статусы поданного предложения: такое предложение
+// видят ответственные организации тендера.
+var SubmittedBidStatuses = []BidStatus{BidStatusPublished, BidStatusApproved, BidStatusRejected, BidStatusDisqualified}
+
+func (status BidStatus) Submitted() bool {
+	for _, s := range SubmittedBidStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Bid struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
@@ -39,6 +60,21 @@ type Bid struct {
 	TenderID    string    `json:"tender_id,omitempty"`
 	Version     uint8     `json:"version"`
 	CreatedAt   time.Time `json:"created_at"`
+	// Signature — необязательная подпись автора версии предложения
+	Signature *BidSignature `json:"signature,omitempty"`
+	// Commitment — hex(sha256(price + ":" + salt)) для закрытого тендера;
+	// Price и Salt появляются после раскрытия
+	Commitment string     `json:"commitment,omitempty"`
+	Price      string     `json:"price,omitempty"`
+	Salt       string     `json:"salt,omitempty"`
+	RevealedAt *time.Time `json:"revealed_at,omitempty"`
 }
 
 type BidList []Bid
+
+// BidCommitment вычисляет обязательство для закрытого тендера. price
+// записывается с двумя знаками после точки, например "1500.00".
+func BidCommitment(price string, salt string) string {
+	sum := sha256.Sum256([]byte(price + ":" + salt))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+type Employee struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+	IsAdmin   bool   `json:"is_admin,omitempty"`
+	// Role — роль в организации, только в списке ее ответственных
+	Role      OrganizationRole `json:"role,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type EmployeeList []Employee
+
+// Invitation — приглашение сотрудника в ответственные организации. Token
+// отдается только при создании, принимается один раз до ExpiresAt.
+type Invitation struct {
+	ID             string           `json:"id"`
+	OrganizationID string           `json:"organization_id"`
+	Username       string           `json:"username"`
+	Role           OrganizationRole `json:"role"`
+	Token          string           `json:"token,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	AcceptedAt     *time.Time       `json:"accepted_at,omitempty"`
+}
@@ -0,0 +1,81 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+var (
+	EventTenderCreated       EventType = "tender.created"
+	EventTenderEdited        EventType = "tender.edited"
+	EventTenderPublished     EventType = "tender.published"
+	EventTenderStatusChanged EventType = "tender.status_changed"
+	EventTenderDeadline      EventType = "tender.deadline_approaching"
+	EventBidCreated          EventType = "bid.created"
+	EventBidEdited           EventType = "bid.edited"
+	EventBidStatusChanged    EventType = "bid.status_changed"
+	EventBidRevealed         EventType = "bid.revealed"
+	EventBidDisqualified     EventType = "bid.disqualified"
+	EventBidApproved         EventType = "bid.approved"
+	EventBidRejected         EventType = "bid.rejected"
+)
+
+var eventTypes = []EventType{
+	EventTenderCreated, EventTenderEdited, EventTenderPublished, EventTenderStatusChanged, EventTenderDeadline,
+	EventBidCreated, EventBidEdited, EventBidStatusChanged, EventBidRevealed,
+	EventBidDisqualified, EventBidApproved, EventBidRejected,
+}
+
+func (e *EventType) Scan(str string) {
+	for _, typ := range eventTypes {
+		if string(typ) == str {
+			*e = typ
+			return
+		}
+	}
+	*e = ""
+}
+
+// Event — доменное событие. OrganizationID — организация тендера,
+// ее подписчики получают событие.
+type Event struct {
+	ID             string          `json:"id"`
+	Type           EventType       `json:"type"`
+	OrganizationID string          `json:"organization_id"`
+	TenderID       string          `json:"tender_id"`
+	BidID          string          `json:"bid_id,omitempty"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// Aggregate возвращает сущность, в пределах которой события публикуются по порядку.
+func (e Event) Aggregate() (string, string) {
+	if e.BidID != "" {
+		return "bid", e.BidID
+	}
+	return "tender", e.TenderID
+}
+
+// OutboxEntry — событие в очереди публикации.
+type OutboxEntry struct {
+	ID       int64
+	Event    Event
+	Attempts int
+	// Sinks — получатели, которые уже приняли событие
+	Sinks []string
+}
+
+// StreamEvent — событие в потоке изменений тендера для фронтенда. Data есть
+// только у событий, которые подписчику можно видеть целиком.
+type StreamEvent struct {
+	ID         int64           `json:"-"`
+	Type       EventType       `json:"type"`
+	TenderID   string          `json:"tender_id"`
+	BidID      string          `json:"bid_id,omitempty"`
+	Status     string          `json:"status,omitempty"`
+	BidCount   *int            `json:"bid_count,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// EmployeeKey — открытый ключ Ed25519 сотрудника в base64.
+type EmployeeKey struct {
+	ID         string     `json:"id"`
+	EmployeeID string     `json:"employee_id"`
+	PublicKey  string     `json:"public_key"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type EmployeeKeyList []EmployeeKey
+
+// BidSignature — отсоединенная подпись версии предложения. В запросе
+// клиент передает KeyID и Signature, остальные поля заполняет сервис.
+type BidSignature struct {
+	BidID     string    `json:"bid_id,omitempty"`
+	Version   uint8     `json:"version,omitempty"`
+	KeyID     string    `json:"key_id"`
+	PublicKey string    `json:"public_key,omitempty"`
+	Payload   string    `json:"payload,omitempty"`
+	Signature string    `json:"signature"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type BidSignatureList []BidSignature
+
+// SigningPayload возвращает каноническую JSON-форму версии предложения,
+// которую подписывает автор: ключи по алфавиту, без пробелов и без
+// экранирования HTML-символов. Обязательство закрытого тендера входит в
+// подпись, для открытого это пустая строка.
+func (b Bid) SigningPayload() ([]byte, error) {
+	payload := struct {
+		AuthorID    string `json:"author_id"`
+		AuthorType  string `json:"author_type"`
+		Commitment  string `json:"commitment"`
+		Description string `json:"description"`
+		Name        string `json:"name"`
+		TenderID    string `json:"tender_id"`
+		Version     uint8  `json:"version"`
+	}{b.AuthorID, b.AuthorType, b.Commitment, b.Description, b.Name, b.TenderID, b.Version}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(payload); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
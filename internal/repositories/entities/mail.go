@@ -0,0 +1,53 @@
+package entities
+
+import "time"
+
+type MailMode string
+
+var (
+	MailModeOff     MailMode = "off"
+	MailModeInstant MailMode = "instant"
+	MailModeDigest  MailMode = "digest"
+)
+
+func (m *MailMode) Scan(str string) {
+	switch str {
+	case "off":
+		*m = MailModeOff
+	case "instant":
+		*m = MailModeInstant
+	case "digest":
+		*m = MailModeDigest
+	default:
+		*m = ""
+	}
+}
+
+// MailSettings — адрес, язык писем и режим: сразу, раз в сутки сводкой
+// или не присылать.
+type MailSettings struct {
+	Email  string   `json:"email"`
+	Locale string   `json:"locale"`
+	Mode   MailMode `json:"mode"`
+}
+
+// MailRecipient — получатель письма; Since — начало периода сводки.
+type MailRecipient struct {
+	UserID    string
+	Email     string
+	Locale    string
+	FirstName string
+	Since     time.Time
+}
+
+// MailMessage — отрисованное письмо в очереди отправки.
+type MailMessage struct {
+	ID       string
+	UserID   string
+	Template string
+	To       string
+	Subject  string
+	Text     string
+	HTML     string
+	Attempts int
+}
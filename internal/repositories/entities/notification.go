@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Notification struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"-"`
+	EventID   string          `json:"event_id"`
+	EventType EventType       `json:"event_type"`
+	TenderID  string          `json:"tender_id,omitempty"`
+	BidID     string          `json:"bid_id,omitempty"`
+	Title     string          `json:"title"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+}
+
+type NotificationList []Notification
+
+// NotificationPreferences — включены ли уведомления по типу события.
+type NotificationPreferences map[EventType]bool
@@ -0,0 +1,46 @@
+package entities
+
+import "time"
+
+type OrganizationType string
+
+var (
+	OrganizationTypeIE  OrganizationType = "IE"
+	OrganizationTypeLLC OrganizationType = "LLC"
+	OrganizationTypeJSC OrganizationType = "JSC"
+)
+
+func (t *OrganizationType) Scan(str string) {
+	switch str {
+	case "IE":
+		*t = OrganizationTypeIE
+	case "LLC":
+		*t = OrganizationTypeLLC
+	case "JSC":
+		*t = OrganizationTypeJSC
+	default:
+		*t = ""
+	}
+}
+
+type Organization struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Type        OrganizationType `json:"type"`
+	INN         string           `json:"inn,omitempty"`
+	OGRN        string           `json:"ogrn,omitempty"`
+	KPP         string           `json:"kpp,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   *time.Time       `json:"updated_at,omitempty"`
+}
+
+type OrganizationList []Organization
+
+// OrganizationSummary — организация в списках тендеров.
+type OrganizationSummary struct {
+	ID   string           `json:"id"`
+	Name string           `json:"name"`
+	Type OrganizationType `json:"type,omitempty"`
+	INN  string           `json:"inn,omitempty"`
+}
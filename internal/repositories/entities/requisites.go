@@ -0,0 +1,87 @@
+package entities
+
+import (
+	"math/big"
+	"regexp"
+)
+
+var kppRe = regexp.MustCompile(`^\d{4}[0-9A-Z]{2}\d{3}$`)
+
+// ValidINN проверяет ИНН: 10 цифр у юридического лица, 12 — у ИП.
+func ValidINN(s string) bool {
+	d, ok := digits(s)
+	if !ok {
+		return false
+	}
+	switch len(d) {
+	case 10:
+		return innCheck(d[:9], []int{2, 4, 10, 3, 5, 9, 4, 6, 8}) == d[9]
+	case 12:
+		return innCheck(d[:10], []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == d[10] &&
+			innCheck(d[:11], []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}) == d[11]
+	}
+	return false
+}
+
+// ValidOGRN проверяет ОГРН юридического лица (13 цифр) или ОГРНИП
+// (15 цифр): последняя цифра — остаток от деления остальных на 11 или 13
+// соответственно.
+func ValidOGRN(s string) bool {
+	d, ok := digits(s)
+	if !ok {
+		return false
+	}
+	// Первая цифра ОГРН — 1 или 5, ОГРНИП — 3.
+	var mod int64
+	switch {
+	case len(d) == 13 && (d[0] == 1 || d[0] == 5):
+		mod = 11
+	case len(d) == 15 && d[0] == 3:
+		mod = 13
+	default:
+		return false
+	}
+	n, _ := new(big.Int).SetString(s[:len(s)-1], 10)
+	check := new(big.Int).Mod(n, big.NewInt(mod)).Int64() % 10
+	return int(check) == d[len(d)-1]
+}
+
+// ValidKPP проверяет формат КПП: код налоговой, причина постановки на учет
+// (цифры или латинские буквы) и порядковый номер.
+func ValidKPP(s string) bool {
+	return kppRe.MatchString(s)
+}
+
+// ValidRequisites проверяет реквизиты по типу организации: у ИП ИНН из 12
+// цифр, ОГРНИП и нет КПП, у LLC и JSC — ИНН из 10 цифр, ОГРН и КПП.
+func (o Organization) ValidRequisites() bool {
+	if !ValidINN(o.INN) || !ValidOGRN(o.OGRN) {
+		return false
+	}
+	if o.Type == OrganizationTypeIE {
+		return len(o.INN) == 12 && len(o.OGRN) == 15 && o.KPP == ""
+	}
+	return len(o.INN) == 10 && len(o.OGRN) == 13 && ValidKPP(o.KPP)
+}
+
+func innCheck(d []int, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += d[i] * w
+	}
+	return sum % 11 % 10
+}
+
+func digits(s string) ([]int, bool) {
+	if s == "" {
+		return nil, false
+	}
+	d := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return nil, false
+		}
+		d[i] = int(s[i] - '0')
+	}
+	return d, true
+}
@@ -0,0 +1,89 @@
+package entities
+
+import "testing"
+
+func TestValidINN(t *testing.T) {
+	for _, tc := range []struct {
+		inn  string
+		want bool
+	}{
+		{"7707083893", true},
+		{"7712345671", true},
+		{"7707083894", false},
+		{"500100732259", true},
+		{"773173084809", true},
+		// сходится первая контрольная цифра, но не вторая
+		{"500100732258", false},
+		{"770708389", false},
+		{"77070838931", false},
+		{"77O7083893", false},
+		{"", false},
+	} {
+		if got := ValidINN(tc.inn); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.inn, got, tc.want)
+		}
+	}
+}
+
+func TestValidOGRN(t *testing.T) {
+	for _, tc := range []struct {
+		ogrn string
+		want bool
+	}{
+		{"1027700132195", true},
+		{"1187746001233", true},
+		{"5077746887312", true},
+		{"1027700132196", false},
+		// ОГРН начинается с 1 или 5
+		{"2027700132195", false},
+		{"304500116000157", true},
+		{"304500116000158", false},
+		// ОГРНИП начинается с 3
+		{"104500116000157", false},
+		{"10277001321950", false},
+		{"1027700I32195", false},
+	} {
+		if got := ValidOGRN(tc.ogrn); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.ogrn, got, tc.want)
+		}
+	}
+}
+
+func TestValidKPP(t *testing.T) {
+	for _, tc := range []struct {
+		kpp  string
+		want bool
+	}{
+		{"771201001", true},
+		{"7712AB001", true},
+		{"7712ab001", false},
+		{"77120100", false},
+		{"7712010011", false},
+		{"A71201001", false},
+		{"", false},
+	} {
+		if got := ValidKPP(tc.kpp); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.kpp, got, tc.want)
+		}
+	}
+}
+
+func TestValidRequisites(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		org  Organization
+		want bool
+	}{
+		{"llc", Organization{Type: OrganizationTypeLLC, INN: "7712345671", OGRN: "1187746001233", KPP: "771201001"}, true},
+		{"llc without kpp", Organization{Type: OrganizationTypeLLC, INN: "7712345671", OGRN: "1187746001233"}, false},
+		{"llc with ogrnip", Organization{Type: OrganizationTypeLLC, INN: "7712345671", OGRN: "304500116000157", KPP: "771201001"}, false},
+		{"jsc", Organization{Type: OrganizationTypeJSC, INN: "7707083893", OGRN: "1027700132195", KPP: "773601001"}, true},
+		{"ie", Organization{Type: OrganizationTypeIE, INN: "500100732259", OGRN: "304500116000157"}, true},
+		{"ie with kpp", Organization{Type: OrganizationTypeIE, INN: "500100732259", OGRN: "304500116000157", KPP: "771201001"}, false},
+		{"ie with legal inn", Organization{Type: OrganizationTypeIE, INN: "7712345671", OGRN: "304500116000157"}, false},
+	} {
+		if got := tc.org.ValidRequisites(); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
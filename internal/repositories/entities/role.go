@@ -0,0 +1,76 @@
+package entities
+
+type OrganizationRole string
+
+var (
+	RoleOwner              OrganizationRole = "owner"
+	RoleProcurementManager OrganizationRole = "procurement_manager"
+	RoleEvaluator          OrganizationRole = "evaluator"
+	RoleViewer             OrganizationRole = "viewer"
+	RoleBidder             OrganizationRole = "bidder"
+)
+
+func (r *OrganizationRole) Scan(str string) {
+	switch str {
+	case "owner":
+		*r = RoleOwner
+	case "procurement_manager":
+		*r = RoleProcurementManager
+	case "evaluator":
+		*r = RoleEvaluator
+	case "viewer":
+		*r = RoleViewer
+	case "bidder":
+		*r = RoleBidder
+	default:
+		*r = ""
+	}
+}
+
+type Permission string
+
+var (
+	// PermOrganizationManage — реквизиты, ответственные и роли
+	PermOrganizationManage Permission = "organization.manage"
+	// PermWebhookManage — вебхуки организации и журнал их доставок
+	PermWebhookManage Permission = "webhook.manage"
+	PermTenderCreate  Permission = "tender.create"
+	// PermTenderEdit — изменение тендера и его статуса
+	PermTenderEdit Permission = "tender.edit"
+	// PermTenderView — неопубликованные тендеры, журнал и поток изменений
+	PermTenderView Permission = "tender.view"
+	// PermBidDecide — решение по предложениям на тендеры организации
+	PermBidDecide Permission = "bid.decide"
+	// PermBidCreate — предложения от имени организации
+	PermBidCreate Permission = "bid.create"
+)
+
+// rolePermissions — матрица прав ролей в организации.
+var rolePermissions = map[OrganizationRole][]Permission{
+	RoleOwner: {PermOrganizationManage, PermWebhookManage, PermTenderCreate, PermTenderEdit,
+		PermTenderView, PermBidDecide, PermBidCreate},
+	RoleProcurementManager: {PermTenderCreate, PermTenderEdit, PermTenderView, PermBidDecide},
+	RoleEvaluator:          {PermTenderView, PermBidDecide},
+	RoleViewer:             {PermTenderView},
+	RoleBidder:             {PermBidCreate},
+}
+
+func (r OrganizationRole) Can(p Permission) bool {
+	for _, perm := range rolePermissions[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesWith — роли, дающие право p.
+func RolesWith(p Permission) []OrganizationRole {
+	var res []OrganizationRole
+	for _, r := range []OrganizationRole{RoleOwner, RoleProcurementManager, RoleEvaluator, RoleViewer, RoleBidder} {
+		if r.Can(p) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
@@ -0,0 +1,117 @@
+package entities
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidFilter = errors.New("service_type — construction, delivery или manufacture, бюджет — число с точностью до копеек, budget_min не больше budget_max, inn — 10 или 12 цифр")
+
+	budgetRe = regexp.MustCompile(`^\d{1,18}(\.\d{1,2})?$`)
+)
+
+// ValidBudget — бюджет тендера или граница фильтра: число с точностью до копеек.
+func ValidBudget(s string) bool {
+	return budgetRe.MatchString(s)
+}
+
+// TenderFilter — фильтры списка тендеров; ими же задаются сохраненные поиски.
+// Query — слова, каждое из которых должно встретиться в названии или описании,
+// INN — ИНН организации, объявившей тендер.
+type TenderFilter struct {
+	ServiceType []TenderType `json:"service_type,omitempty"`
+	Query       string       `json:"query,omitempty"`
+	BudgetMin   string       `json:"budget_min,omitempty"`
+	BudgetMax   string       `json:"budget_max,omitempty"`
+	INN         string       `json:"inn,omitempty"`
+}
+
+// Normalize приводит виды услуг к нижнему регистру и проверяет значения.
+func (f *TenderFilter) Normalize() error {
+	for i, s := range f.ServiceType {
+		switch typ := TenderType(strings.ToLower(string(s))); typ {
+		case TenderTypeConstruction, TenderTypeDelivery, TenderTypeManufacture:
+			f.ServiceType[i] = typ
+		default:
+			return ErrInvalidFilter
+		}
+	}
+	f.Query = strings.Join(strings.Fields(f.Query), " ")
+	for _, b := range []string{f.BudgetMin, f.BudgetMax} {
+		if b != "" && !ValidBudget(b) {
+			return ErrInvalidFilter
+		}
+	}
+	f.INN = strings.TrimSpace(f.INN)
+	if f.INN != "" && !ValidINN(f.INN) {
+		return ErrInvalidFilter
+	}
+	if f.BudgetMin != "" && f.BudgetMax != "" && compareBudget(f.BudgetMin, f.BudgetMax) > 0 {
+		return ErrInvalidFilter
+	}
+	return nil
+}
+
+func (f TenderFilter) Empty() bool {
+	return len(f.ServiceType) == 0 && f.Query == "" && f.BudgetMin == "" && f.BudgetMax == "" && f.INN == ""
+}
+
+// compareBudget сравнивает бюджеты, прошедшие ValidBudget.
+func compareBudget(a, b string) int {
+	split := func(s string) (string, string) {
+		whole, frac, _ := strings.Cut(s, ".")
+		whole = strings.TrimLeft(whole, "0")
+		return whole, (frac + "00")[:2]
+	}
+	aw, af := split(a)
+	bw, bf := split(b)
+	if len(aw) != len(bw) {
+		if len(aw) < len(bw) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(aw+af, bw+bf)
+}
+
+type SearchChannel string
+
+var (
+	SearchChannelNotification SearchChannel = "notification"
+	SearchChannelEmail        SearchChannel = "email"
+)
+
+func (c *SearchChannel) Scan(str string) {
+	switch str {
+	case "notification":
+		*c = SearchChannelNotification
+	case "email":
+		*c = SearchChannelEmail
+	default:
+		*c = ""
+	}
+}
+
+// SavedSearch — сохраненный поиск сотрудника. О каждом опубликованном
+// тендере, подходящем под фильтры, приходит уведомление, а при канале
+// email — еще и письмо.
+type SavedSearch struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	TenderFilter
+	Channel   SearchChannel `json:"channel"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type SavedSearchList []SavedSearch
+
+// SearchMatch — сохраненный поиск, под который подошел тендер.
+type SearchMatch struct {
+	SearchID string
+	UserID   string
+	Name     string
+	Channel  SearchChannel
+}
@@ -28,15 +28,23 @@ func (status *TenderStatus) Scan(str string) {
 }
 
 type Tender struct {
-	ID              string       `json:"id"`
-	Name            string       `json:"name"`
-	Description     string       `json:"description,omitempty"`
-	ServiceType     TenderType   `json:"service_type,omitempty"`
-	Status          TenderStatus `json:"status"`
-	OrganizationID  string       `json:"organization_id,omitempty"`
-	CreatorUsername string       `json:"username"`
-	Version         uint8        `json:"version"`
-	CreatedAt       time.Time    `json:"created_at"`
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	Description    string       `json:"description,omitempty"`
+	ServiceType    TenderType   `json:"service_type,omitempty"`
+	Status         TenderStatus `json:"status"`
+	OrganizationID string       `json:"organization_id,omitempty"`
+	// Organization заполняется в списках тендеров
+	Organization    *OrganizationSummary `json:"organization,omitempty"`
+	CreatorUsername string               `json:"username"`
+	Version         uint8                `json:"version"`
+	CreatedAt       time.Time            `json:"created_at"`
+	// Sealed — закрытый тендер с обязательствами и раскрытием цены
+	Sealed         bool       `json:"sealed,omitempty"`
+	BidDeadline    *time.Time `json:"bid_deadline,omitempty"`
+	RevealDeadline *time.Time `json:"reveal_deadline,omitempty"`
+	// Budget — ориентировочный бюджет, число с точностью до копеек
+	Budget string `json:"budget,omitempty"`
 }
 
 type TenderList []Tender
@@ -0,0 +1,8 @@
+package entities
+
+// Viewer — от чьего имени читаются данные: ID — id сотрудника, "" — аноним.
+// All снимает ограничения видимости; только для tenderctl и фоновых задач.
+type Viewer struct {
+	ID  string
+	All bool
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrKeyNotFound = errors.New("ключ не найден")
+
+type Key interface {
+	Create(ctx context.Context, key entities.EmployeeKey) (entities.EmployeeKey, error)
+	GetByID(ctx context.Context, id string) (entities.EmployeeKey, error)
+	GetByEmployee(ctx context.Context, employeeId string) (entities.EmployeeKeyList, error)
+	Revoke(ctx context.Context, id string, employeeId string) (entities.EmployeeKey, error)
+	CreateSignature(ctx context.Context, sig entities.BidSignature) (entities.BidSignature, error)
+	GetBidSignatures(ctx context.Context, bidId string) (entities.BidSignatureList, error)
+}
+
+type KeyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewKeyRepo(db *pgxpool.Pool) Key {
+	return &KeyRepo{db: db}
+}
+
+const keyColumns = `id, employee_id, public_key, created_at, revoked_at`
+
+func (t *KeyRepo) Create(ctx context.Context, key entities.EmployeeKey) (entities.EmployeeKey, error) {
+	query := `
+		insert into employee_key(employee_id, public_key) values ($1, $2)
+		on conflict (employee_id, public_key) do update set public_key=excluded.public_key
+		returning ` + keyColumns
+	var res entities.EmployeeKey
+	err := conn(ctx, t.db).QueryRow(ctx, query, key.EmployeeID, key.PublicKey).Scan(
+		&res.ID, &res.EmployeeID, &res.PublicKey, &res.CreatedAt, &res.RevokedAt)
+	if err != nil {
+		return entities.EmployeeKey{}, err
+	}
+	return res, nil
+}
+
+func (t *KeyRepo) GetByID(ctx context.Context, id string) (entities.EmployeeKey, error) {
+	query := `select ` + keyColumns + ` from employee_key where id=$1`
+	var res entities.EmployeeKey
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(
+			&res.ID, &res.EmployeeID, &res.PublicKey, &res.CreatedAt, &res.RevokedAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.EmployeeKey{}, ErrKeyNotFound
+		}
+		return entities.EmployeeKey{}, err
+	}
+	return res, nil
+}
+
+func (t *KeyRepo) GetByEmployee(ctx context.Context, employeeId string) (entities.EmployeeKeyList, error) {
+	query := `select ` + keyColumns + ` from employee_key where employee_id=$1 order by created_at`
+	var res entities.EmployeeKeyList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, employeeId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var key entities.EmployeeKey
+			err := rows.Scan(&key.ID, &key.EmployeeID, &key.PublicKey, &key.CreatedAt, &key.RevokedAt)
+			if err != nil {
+				return err
+			}
+			res = append(res, key)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.EmployeeKeyList{}, err
+	}
+	return res, nil
+}
+
+// Revoke отзывает ключ сотрудника; повторный отзыв сохраняет первую дату.
+func (t *KeyRepo) Revoke(ctx context.Context, id string, employeeId string) (entities.EmployeeKey, error) {
+	query := `
+		update employee_key set revoked_at=coalesce(revoked_at, now())
+		where id=$1 and employee_id=$2
+		returning ` + keyColumns
+	var res entities.EmployeeKey
+	err := conn(ctx, t.db).QueryRow(ctx, query, id, employeeId).Scan(
+		&res.ID, &res.EmployeeID, &res.PublicKey, &res.CreatedAt, &res.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.EmployeeKey{}, ErrKeyNotFound
+		}
+		return entities.EmployeeKey{}, err
+	}
+	return res, nil
+}
+
+func (t *KeyRepo) CreateSignature(ctx context.Context, sig entities.BidSignature) (entities.BidSignature, error) {
+	query := `
+		insert into bid_signature(bid_id, version, key_id, payload, signature)
+		values ($1, $2, $3, $4, $5)
+		returning created_at
+	`
+	err := conn(ctx, t.db).QueryRow(ctx, query, sig.BidID, sig.Version, sig.KeyID, sig.Payload, sig.Signature).
+		Scan(&sig.CreatedAt)
+	if err != nil {
+		return entities.BidSignature{}, err
+	}
+	return sig, nil
+}
+
+func (t *KeyRepo) GetBidSignatures(ctx context.Context, bidId string) (entities.BidSignatureList, error) {
+	query := `
+		select s.bid_id, s.version, s.key_id, k.public_key, s.payload, s.signature, s.created_at
+		from bid_signature s join employee_key k on k.id=s.key_id
+		where s.bid_id=$1 order by s.version
+	`
+	var res entities.BidSignatureList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, bidId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var sig entities.BidSignature
+			err := rows.Scan(&sig.BidID, &sig.Version, &sig.KeyID, &sig.PublicKey,
+				&sig.Payload, &sig.Signature, &sig.CreatedAt)
+			if err != nil {
+				return err
+			}
+			res = append(res, sig)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.BidSignatureList{}, err
+	}
+	return res, nil
+}
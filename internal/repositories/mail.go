@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Mail interface {
+	GetRecipients(ctx context.Context, userIds []string) ([]entities.MailRecipient, error)
+	GetDigestRecipients(ctx context.Context, period time.Duration) ([]entities.MailRecipient, error)
+	MarkDigest(ctx context.Context, userId string) error
+	GetSettings(ctx context.Context, userId string) (entities.MailSettings, error)
+	SetSettings(ctx context.Context, userId string, settings entities.MailSettings) error
+	Enqueue(ctx context.Context, msg entities.MailMessage) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]entities.MailMessage, error)
+	Complete(ctx context.Context, id string) error
+	Fail(ctx context.Context, id string, reason string, retryIn time.Duration) error
+}
+
+type MailRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMailRepo(db *pgxpool.Pool) Mail {
+	return &MailRepo{db: db}
+}
+
+// GetRecipients возвращает из userIds тех, у кого есть адрес и письма
+// приходят сразу.
+func (t *MailRepo) GetRecipients(ctx context.Context, userIds []string) ([]entities.MailRecipient, error) {
+	query := `
+		select e.id, e.email, e.locale, coalesce(e.first_name, ''), now()::timestamp from employee e
+		left join mail_settings m on m.user_id=e.id
+		where e.id = any($1::uuid[]) and coalesce(e.email, '') <> ''
+		and coalesce(m.mode, 'instant')='instant'
+	`
+	return t.recipients(ctx, query, userIds)
+}
+
+// GetDigestRecipients возвращает получателей сводки, с последней сводки
+// которых прошло не меньше period. Since — время последней сводки или
+// начало period.
+func (t *MailRepo) GetDigestRecipients(ctx context.Context, period time.Duration) ([]entities.MailRecipient, error) {
+	query := `
+		select e.id, e.email, e.locale, coalesce(e.first_name, ''),
+		coalesce(m.last_digest_at, (now() - make_interval(secs => $1::float8))::timestamp)
+		from employee e join mail_settings m on m.user_id=e.id
+		where m.mode='digest' and coalesce(e.email, '') <> ''
+		and (m.last_digest_at is null or m.last_digest_at <= now() - make_interval(secs => $1::float8))
+	`
+	return t.recipients(ctx, query, period.Seconds())
+}
+
+func (t *MailRepo) recipients(ctx context.Context, query string, arg any) ([]entities.MailRecipient, error) {
+	var res []entities.MailRecipient
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, arg)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var r entities.MailRecipient
+			if err := rows.Scan(&r.UserID, &r.Email, &r.Locale, &r.FirstName, &r.Since); err != nil {
+				return err
+			}
+			res = append(res, r)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *MailRepo) MarkDigest(ctx context.Context, userId string) error {
+	query := `update mail_settings set last_digest_at=now() where user_id=$1`
+	_, err := conn(ctx, t.db).Exec(ctx, query, userId)
+	return err
+}
+
+func (t *MailRepo) GetSettings(ctx context.Context, userId string) (entities.MailSettings, error) {
+	query := `
+		select coalesce(e.email, ''), e.locale, coalesce(m.mode, 'instant') from employee e
+		left join mail_settings m on m.user_id=e.id where e.id=$1
+	`
+	var res entities.MailSettings
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, userId).Scan(&res.Email, &res.Locale, &res.Mode)
+	})
+	if err != nil {
+		return entities.MailSettings{}, err
+	}
+	return res, nil
+}
+
+func (t *MailRepo) SetSettings(ctx context.Context, userId string, settings entities.MailSettings) error {
+	query := `
+		with e as (
+			update employee set email=nullif($2, ''), locale=$3, updated_at=now() where id=$1
+		)
+		insert into mail_settings(user_id, mode) values ($1, $4)
+		on conflict (user_id) do update set mode=excluded.mode
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, userId, settings.Email, settings.Locale, settings.Mode)
+	return err
+}
+
+func (t *MailRepo) Enqueue(ctx context.Context, msg entities.MailMessage) error {
+	query := `
+		insert into mail_queue(user_id, template, to_address, subject, body_text, body_html)
+		values (nullif($1, '')::uuid, $2, $3, $4, $5, $6)
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, msg.UserID, msg.Template, msg.To, msg.Subject, msg.Text, msg.HTML)
+	return err
+}
+
+// Claim забирает готовые к отправке письма и откладывает их следующую
+// попытку на lease — так же, как WebhookRepo.ClaimDeliveries.
+func (t *MailRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]entities.MailMessage, error) {
+	query := `
+		update mail_queue set next_attempt_at=now() + make_interval(secs => $2::float8)
+		where id in (
+			select id from mail_queue
+			where status='pending' and next_attempt_at <= now()
+			order by next_attempt_at limit $1
+			for update skip locked
+		)
+		returning id, coalesce(user_id::text, ''), template, to_address, subject, body_text, body_html, attempts
+	`
+	var res []entities.MailMessage
+	rows, err := conn(ctx, t.db).Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m entities.MailMessage
+		err := rows.Scan(&m.ID, &m.UserID, &m.Template, &m.To, &m.Subject, &m.Text, &m.HTML, &m.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+func (t *MailRepo) Complete(ctx context.Context, id string) error {
+	query := `
+		update mail_queue set status='sent', attempts=attempts+1, last_error=null, sent_at=now()
+		where id=$1
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id)
+	return err
+}
+
+// Fail записывает неудачную попытку; retryIn <= 0 — попытки исчерпаны.
+func (t *MailRepo) Fail(ctx context.Context, id string, reason string, retryIn time.Duration) error {
+	query := `
+		update mail_queue set attempts=attempts+1, last_error=$2,
+		status=case when $3::float8 > 0 then 'pending' else 'failed' end,
+		next_attempt_at=case when $3::float8 > 0 then now() + make_interval(secs => $3::float8) else next_attempt_at end
+		where id=$1
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id, reason, retryIn.Seconds())
+	return err
+}
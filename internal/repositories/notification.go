@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNotificationNotFound = errors.New("уведомление не найдено")
+
+type Notification interface {
+	Create(ctx context.Context, n entities.Notification, userIds []string) ([]string, error)
+	GetByUser(ctx context.Context, userId string, unread bool, limit int, offset int) (entities.NotificationList, error)
+	GetSince(ctx context.Context, userId string, since time.Time, limit int) (entities.NotificationList, error)
+	MarkRead(ctx context.Context, id string, userId string) (entities.Notification, error)
+	MarkAllRead(ctx context.Context, userId string) (int, error)
+	GetPreferences(ctx context.Context, userId string) (entities.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, userId string, prefs entities.NotificationPreferences) error
+	GetTenderBidders(ctx context.Context, tenderId string) ([]string, error)
+	GetResponsibles(ctx context.Context, organizationId string, roles []entities.OrganizationRole) ([]string, error)
+}
+
+type NotificationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepo(db *pgxpool.Pool) Notification {
+	return &NotificationRepo{db: db}
+}
+
+const notificationColumns = `id, user_id, event_id, event_type, coalesce(tender_id::text, ''),
+	coalesce(bid_id::text, ''), title, payload, created_at, read_at`
+
+func scanNotification(row pgx.Row) (entities.Notification, error) {
+	var n entities.Notification
+	err := row.Scan(&n.ID, &n.UserID, &n.EventID, &n.EventType, &n.TenderID,
+		&n.BidID, &n.Title, &n.Payload, &n.CreatedAt, &n.ReadAt)
+	return n, err
+}
+
+// Create создает уведомление каждому из userIds, кроме отключивших этот тип
+// событий, и возвращает тех, кому оно создано. Повтор того же события не
+// создает дублей.
+func (t *NotificationRepo) Create(ctx context.Context, n entities.Notification, userIds []string) ([]string, error) {
+	query := `
+		insert into notification(user_id, event_id, event_type, tender_id, bid_id, title, payload)
+		select u.id, $2, $3, nullif($4, '')::uuid, nullif($5, '')::uuid, $6, $7
+		from unnest($1::uuid[]) as u(id)
+		where not exists (
+			select 1 from notification_preference p
+			where p.user_id=u.id and p.event_type=$3 and not p.enabled
+		)
+		on conflict (user_id, event_id) do nothing
+		returning user_id
+	`
+	rows, err := conn(ctx, t.db).Query(ctx, query, userIds, n.EventID, n.EventType, n.TenderID, n.BidID, n.Title, n.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (t *NotificationRepo) GetByUser(ctx context.Context, userId string, unread bool, limit int, offset int) (entities.NotificationList, error) {
+	query := `
+		select ` + notificationColumns + ` from notification
+		where user_id=$1 and (not $2 or read_at is null)
+		order by created_at desc, id limit $3 offset $4
+	`
+	var res entities.NotificationList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, userId, unread, limit, offset)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			n, err := scanNotification(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, n)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.NotificationList{}, err
+	}
+	return res, nil
+}
+
+func (t *NotificationRepo) GetSince(ctx context.Context, userId string, since time.Time, limit int) (entities.NotificationList, error) {
+	query := `
+		select ` + notificationColumns + ` from notification
+		where user_id=$1 and created_at > $2
+		order by created_at, id limit $3
+	`
+	var res entities.NotificationList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, userId, since, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			n, err := scanNotification(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, n)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.NotificationList{}, err
+	}
+	return res, nil
+}
+
+// MarkRead отмечает уведомление прочитанным; повторная отметка время не меняет.
+func (t *NotificationRepo) MarkRead(ctx context.Context, id string, userId string) (entities.Notification, error) {
+	query := `
+		update notification set read_at=coalesce(read_at, now())
+		where id=$1 and user_id=$2
+		returning ` + notificationColumns
+	n, err := scanNotification(conn(ctx, t.db).QueryRow(ctx, query, id, userId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Notification{}, ErrNotificationNotFound
+		}
+		return entities.Notification{}, err
+	}
+	return n, nil
+}
+
+func (t *NotificationRepo) MarkAllRead(ctx context.Context, userId string) (int, error) {
+	query := `update notification set read_at=now() where user_id=$1 and read_at is null`
+	tag, err := conn(ctx, t.db).Exec(ctx, query, userId)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (t *NotificationRepo) GetPreferences(ctx context.Context, userId string) (entities.NotificationPreferences, error) {
+	query := `select event_type, enabled from notification_preference where user_id=$1`
+	res := entities.NotificationPreferences{}
+	err := withRetry(ctx, func() error {
+		clear(res)
+		rows, err := conn(ctx, t.db).Query(ctx, query, userId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				typ     entities.EventType
+				enabled bool
+			)
+			if err := rows.Scan(&typ, &enabled); err != nil {
+				return err
+			}
+			res[typ] = enabled
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *NotificationRepo) SetPreferences(ctx context.Context, userId string, prefs entities.NotificationPreferences) error {
+	query := `
+		insert into notification_preference(user_id, event_type, enabled) values ($1, $2, $3)
+		on conflict (user_id, event_type) do update set enabled=excluded.enabled
+	`
+	batch := &pgx.Batch{}
+	for typ, enabled := range prefs {
+		batch.Queue(query, userId, typ, enabled)
+	}
+	return conn(ctx, t.db).SendBatch(ctx, batch).Close()
+}
+
+// GetTenderBidders возвращает авторов предложений тендера.
+func (t *NotificationRepo) GetTenderBidders(ctx context.Context, tenderId string) ([]string, error) {
+	return t.ids(ctx, `select distinct author_id from bid where tender_id=$1`, tenderId)
+}
+
+// GetResponsibles возвращает ответственных организации с одной из ролей roles.
+func (t *NotificationRepo) GetResponsibles(ctx context.Context, organizationId string, roles []entities.OrganizationRole) ([]string, error) {
+	names := []string{}
+	for _, r := range roles {
+		names = append(names, string(r))
+	}
+	return t.ids(ctx, `select user_id from organization_responsible where organization_id=$1 and role = any($2::text[])`,
+		organizationId, names)
+}
+
+func (t *NotificationRepo) ids(ctx context.Context, query string, args ...any) ([]string, error) {
+	var res []string
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			res = append(res, id)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("организация не найдена")
+	ErrResponsibleNotFound  = errors.New("сотрудник не ответственен за организацию")
+	ErrInvitationNotFound   = errors.New("приглашение не найдено, истекло или уже принято")
+	ErrRequisitesTaken      = errors.New("организация с таким ИНН или ОГРН уже зарегистрирована")
+)
+
+type Organization interface {
+	Create(ctx context.Context, org entities.Organization) (entities.Organization, error)
+	AddResponsible(ctx context.Context, organizationID string, userID string, role entities.OrganizationRole) error
+	SetRole(ctx context.Context, organizationID string, userID string, role entities.OrganizationRole) error
+	GetByID(ctx context.Context, id string) (entities.Organization, error)
+	GetList(ctx context.Context, limit int, offset int) (entities.OrganizationList, error)
+	Edit(ctx context.Context, org entities.Organization, id string) (entities.Organization, error)
+	Delete(ctx context.Context, id string) error
+	HasTenders(ctx context.Context, id string) (bool, error)
+	Lock(ctx context.Context, id string) error
+	GetResponsibles(ctx context.Context, id string) (entities.EmployeeList, error)
+	RemoveResponsible(ctx context.Context, organizationID string, userID string) error
+	CreateInvitation(ctx context.Context, organizationID string, userID string, invitedBy string,
+		role entities.OrganizationRole, tokenHash string, ttl time.Duration) (entities.Invitation, error)
+	AcceptInvitation(ctx context.Context, tokenHash string, userID string) (string, entities.OrganizationRole, error)
+}
+
+type OrganizationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOrganizationRepo(db *pgxpool.Pool) Organization {
+	return &OrganizationRepo{db: db}
+}
+
+const organizationColumns = `id, name, coalesce(description, ''), type, coalesce(inn, ''), coalesce(ogrn, ''),
+	coalesce(kpp, ''), created_at, updated_at`
+
+func scanOrganization(row pgx.Row) (entities.Organization, error) {
+	var res entities.Organization
+	err := row.Scan(&res.ID, &res.Name, &res.Description, &res.Type, &res.INN, &res.OGRN, &res.KPP,
+		&res.CreatedAt, &res.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, ErrOrganizationNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return res, ErrRequisitesTaken
+	}
+	return res, err
+}
+
+func (t *OrganizationRepo) Create(ctx context.Context, org entities.Organization) (entities.Organization, error) {
+	query := `
+		insert into organization(name, description, type, inn, ogrn, kpp)
+		values ($1, $2, $3, $4, $5, nullif($6, ''))
+		returning ` + organizationColumns
+	return scanOrganization(conn(ctx, t.db).QueryRow(ctx, query, org.Name, org.Description, org.Type,
+		org.INN, org.OGRN, org.KPP))
+}
+
+func (t *OrganizationRepo) AddResponsible(ctx context.Context, organizationID string, userID string,
+	role entities.OrganizationRole) error {
+	query := `insert into organization_responsible(organization_id, user_id, role) values ($1, $2, $3)
+	on conflict (organization_id, user_id) do nothing`
+	_, err := conn(ctx, t.db).Exec(ctx, query, organizationID, userID, string(role))
+	return err
+}
+
+func (t *OrganizationRepo) SetRole(ctx context.Context, organizationID string, userID string,
+	role entities.OrganizationRole) error {
+	query := `update organization_responsible set role=$3 where organization_id=$1 and user_id=$2`
+	tag, err := conn(ctx, t.db).Exec(ctx, query, organizationID, userID, string(role))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrResponsibleNotFound
+	}
+	return nil
+}
+
+func (t *OrganizationRepo) GetByID(ctx context.Context, id string) (entities.Organization, error) {
+	query := `select ` + organizationColumns + ` from organization where id=$1`
+	var res entities.Organization
+	err := withRetry(ctx, func() error {
+		var err error
+		res, err = scanOrganization(conn(ctx, t.db).QueryRow(ctx, query, id))
+		return err
+	})
+	if err != nil {
+		return entities.Organization{}, err
+	}
+	return res, nil
+}
+
+func (t *OrganizationRepo) GetList(ctx context.Context, limit int, offset int) (entities.OrganizationList, error) {
+	query := `select ` + organizationColumns + ` from organization order by name ASC, id limit $1 offset $2`
+	var res entities.OrganizationList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, limit, offset)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			org, err := scanOrganization(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, org)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.OrganizationList{}, err
+	}
+	return res, nil
+}
+
+// Edit меняет только непустые поля org.
+func (t *OrganizationRepo) Edit(ctx context.Context, org entities.Organization, id string) (entities.Organization, error) {
+	var sb strings.Builder
+	sb.WriteString(`update organization set updated_at=now()`)
+	args := pgx.NamedArgs{"id": id}
+	if org.Name != "" {
+		sb.WriteString(`, name=@name`)
+		args["name"] = org.Name
+	}
+	if org.Description != "" {
+		sb.WriteString(`, description=@description`)
+		args["description"] = org.Description
+	}
+	if org.Type != "" {
+		sb.WriteString(`, type=@type`)
+		args["type"] = org.Type
+	}
+	if org.INN != "" {
+		sb.WriteString(`, inn=@inn`)
+		args["inn"] = org.INN
+	}
+	if org.OGRN != "" {
+		sb.WriteString(`, ogrn=@ogrn`)
+		args["ogrn"] = org.OGRN
+	}
+	// У ИП нет КПП: при смене типа на IE он стирается.
+	if org.KPP != "" || org.Type == entities.OrganizationTypeIE {
+		sb.WriteString(`, kpp=nullif(@kpp, '')`)
+		args["kpp"] = org.KPP
+	}
+	sb.WriteString(` where id=@id returning ` + organizationColumns)
+	return scanOrganization(conn(ctx, t.db).QueryRow(ctx, sb.String(), args))
+}
+
+func (t *OrganizationRepo) Delete(ctx context.Context, id string) error {
+	query := `delete from organization where id=$1`
+	tag, err := conn(ctx, t.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOrganizationNotFound
+	}
+	return nil
+}
+
+// HasTenders учитывает и тендеры, скрытые от сотрудника политиками RLS:
+// удаление организации удалило бы их каскадом.
+func (t *OrganizationRepo) HasTenders(ctx context.Context, id string) (bool, error) {
+	query := `select exists(select 1 from tender where organization_id=$1)`
+	var res bool
+	ctx = System(ctx)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res)
+	})
+	return res, err
+}
+
+// Lock блокирует организацию до конца транзакции, чтобы параллельные
+// изменения состава ответственных шли по очереди.
+func (t *OrganizationRepo) Lock(ctx context.Context, id string) error {
+	query := `select id from organization where id=$1 for update`
+	err := conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOrganizationNotFound
+	}
+	return err
+}
+
+func (t *OrganizationRepo) GetResponsibles(ctx context.Context, id string) (entities.EmployeeList, error) {
+	query := `
+		select e.id, e.username, coalesce(e.first_name, ''), coalesce(e.last_name, ''), o.role, e.created_at
+		from organization_responsible o join employee e on e.id=o.user_id
+		where o.organization_id=$1 order by e.username
+	`
+	var res entities.EmployeeList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				e    entities.Employee
+				role string
+			)
+			if err := rows.Scan(&e.ID, &e.Username, &e.FirstName, &e.LastName, &role, &e.CreatedAt); err != nil {
+				return err
+			}
+			e.Role.Scan(role)
+			res = append(res, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.EmployeeList{}, err
+	}
+	return res, nil
+}
+
+func (t *OrganizationRepo) RemoveResponsible(ctx context.Context, organizationID string, userID string) error {
+	query := `delete from organization_responsible where organization_id=$1 and user_id=$2`
+	tag, err := conn(ctx, t.db).Exec(ctx, query, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrResponsibleNotFound
+	}
+	return nil
+}
+
+func (t *OrganizationRepo) CreateInvitation(ctx context.Context, organizationID string, userID string, invitedBy string,
+	role entities.OrganizationRole, tokenHash string, ttl time.Duration) (entities.Invitation, error) {
+	query := `
+		insert into organization_invitation(organization_id, user_id, invited_by, role, token_hash, expires_at)
+		values ($1, $2, $3, $4, $5, now() + make_interval(secs => $6::float8))
+		returning id, organization_id, (select username from employee where id=$2), created_at, expires_at
+	`
+	res := entities.Invitation{Role: role}
+	row := conn(ctx, t.db).QueryRow(ctx, query, organizationID, userID, invitedBy, string(role), tokenHash, ttl.Seconds())
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.Username, &res.CreatedAt, &res.ExpiresAt)
+	if err != nil {
+		return entities.Invitation{}, err
+	}
+	return res, nil
+}
+
+// AcceptInvitation гасит приглашение userID по хешу токена и возвращает
+// организацию и роль. Повторно то же приглашение не принимается.
+func (t *OrganizationRepo) AcceptInvitation(ctx context.Context, tokenHash string,
+	userID string) (string, entities.OrganizationRole, error) {
+	query := `
+		update organization_invitation set accepted_at=now()
+		where token_hash=$1 and user_id=$2 and accepted_at is null and expires_at > now()
+		returning organization_id, role
+	`
+	var (
+		res  string
+		role entities.OrganizationRole
+		str  string
+	)
+	err := conn(ctx, t.db).QueryRow(ctx, query, tokenHash, userID).Scan(&res, &str)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrInvitationNotFound
+	}
+	role.Scan(str)
+	return res, role, err
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// outboxLockID — ключ advisory lock диспетчера: публикует только один
+// экземпляр сервиса, иначе порядок событий внутри сущности не гарантирован.
+const outboxLockID = 6107
+
+type Outbox interface {
+	Add(ctx context.Context, event entities.Event) error
+	GetPending(ctx context.Context, limit int) ([]entities.OutboxEntry, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkSinkPublished(ctx context.Context, id int64, sink string) error
+	MarkFailed(ctx context.Context, id int64, reason string, final bool) error
+	TryLock(ctx context.Context) (release func(), ok bool, err error)
+	GetAfter(ctx context.Context, afterId int64, tenderId string, limit int) ([]entities.OutboxEntry, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]entities.OutboxEntry, error)
+	LastID(ctx context.Context) (int64, error)
+	Listen(ctx context.Context, wake chan<- struct{}) error
+}
+
+type OutboxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepo(db *pgxpool.Pool) Outbox {
+	return &OutboxRepo{db: db}
+}
+
+// Add пишет событие в outbox; вызывается в транзакции изменения.
+func (t *OutboxRepo) Add(ctx context.Context, event entities.Event) error {
+	query := `
+		insert into outbox(event_id, aggregate_type, aggregate_id, event_type, payload, tender_id)
+		values ($1, $2, $3, $4, $5, nullif($6, '')::uuid)
+	`
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	aggregateType, aggregateId := event.Aggregate()
+	_, err = conn(ctx, t.db).Exec(ctx, query, event.ID, aggregateType, aggregateId, event.Type, payload, event.TenderID)
+	return err
+}
+
+const outboxSinks = `array(select sink from outbox_sink s where s.outbox_id = outbox.id order by sink)`
+
+func (t *OutboxRepo) GetPending(ctx context.Context, limit int) ([]entities.OutboxEntry, error) {
+	query := `
+		select id, payload, attempts, ` + outboxSinks + ` from outbox
+		where published_at is null and failed_at is null
+		order by id limit $1
+	`
+	return t.list(ctx, query, limit)
+}
+
+// GetAfter возвращает события с id больше afterId независимо от публикации;
+// tenderId == "" — события всех тендеров.
+func (t *OutboxRepo) GetAfter(ctx context.Context, afterId int64, tenderId string, limit int) ([]entities.OutboxEntry, error) {
+	query := `
+		select id, payload, attempts, ` + outboxSinks + ` from outbox
+		where id > $1 and ($2 = '' or tender_id = nullif($2, '')::uuid)
+		order by id limit $3
+	`
+	return t.list(ctx, query, afterId, tenderId, limit)
+}
+
+// GetByIDs возвращает события с данными id, которые уже зафиксированы.
+func (t *OutboxRepo) GetByIDs(ctx context.Context, ids []int64) ([]entities.OutboxEntry, error) {
+	query := `
+		select id, payload, attempts, ` + outboxSinks + ` from outbox
+		where id = any($1) order by id
+	`
+	return t.list(ctx, query, ids)
+}
+
+func (t *OutboxRepo) list(ctx context.Context, query string, args ...any) ([]entities.OutboxEntry, error) {
+	var res []entities.OutboxEntry
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				e       entities.OutboxEntry
+				payload []byte
+			)
+			if err := rows.Scan(&e.ID, &payload, &e.Attempts, &e.Sinks); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(payload, &e.Event); err != nil {
+				return err
+			}
+			res = append(res, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *OutboxRepo) LastID(ctx context.Context) (int64, error) {
+	var id int64
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, `select coalesce(max(id), 0) from outbox`).Scan(&id)
+	})
+	return id, err
+}
+
+// Listen подписывается на уведомления о новых событиях и будит wake на
+// каждое. Забирает соединение из пула и держит его до ошибки или отмены ctx.
+func (t *OutboxRepo) Listen(ctx context.Context, wake chan<- struct{}) error {
+	c, err := t.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// после LISTEN соединение нельзя возвращать в пул как обычное
+	pgConn := c.Hijack()
+	defer pgConn.Close(context.Background())
+	if _, err := pgConn.Exec(ctx, `listen outbox_events`); err != nil {
+		return err
+	}
+	for {
+		if _, err := pgConn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (t *OutboxRepo) MarkPublished(ctx context.Context, id int64) error {
+	query := `update outbox set published_at=now(), attempts=attempts+1, last_error=null where id=$1`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id)
+	return err
+}
+
+// MarkSinkPublished отмечает, что получатель sink принял событие id.
+func (t *OutboxRepo) MarkSinkPublished(ctx context.Context, id int64, sink string) error {
+	query := `insert into outbox_sink(outbox_id, sink) values ($1, $2) on conflict do nothing`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id, sink)
+	return err
+}
+
+// MarkFailed записывает неудачную попытку; final — попытки исчерпаны.
+func (t *OutboxRepo) MarkFailed(ctx context.Context, id int64, reason string, final bool) error {
+	query := `
+		update outbox set attempts=attempts+1, last_error=$2,
+		failed_at=case when $3 then now() end
+		where id=$1
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id, reason, final)
+	return err
+}
+
+// TryLock берет lock диспетчера на отдельном соединении. ok == false —
+// публикует другой экземпляр.
+func (t *OutboxRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	c, err := t.db.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := c.QueryRow(ctx, `select pg_try_advisory_lock($1)`, outboxLockID).Scan(&ok); err != nil {
+		c.Release()
+		return nil, false, err
+	}
+	if !ok {
+		c.Release()
+		return nil, false, nil
+	}
+	return func() {
+		c.Exec(context.Background(), `select pg_advisory_unlock($1)`, outboxLockID)
+		c.Release()
+	}, true, nil
+}
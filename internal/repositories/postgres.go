@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"zadanie-6105/internal/config"
+	"zadanie-6105/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	connectBackoff    = 200 * time.Millisecond
+	connectMaxBackoff = 5 * time.Second
+)
+
+// NewPool создает пул соединений сервиса и ждет доступности базы, повторяя
+// попытки с экспоненциальной задержкой до cfg.ConnectTimeout или отмены ctx.
+// Перед выдачей соединения пул выставляет переменные RLS по viewer из ctx.
+func NewPool(ctx context.Context, cfg config.Postgres, logger *zap.Logger) (*pgxpool.Pool, error) {
+	db, err := newPool(ctx, cfg, logger, sessionHook(logger))
+	if err != nil {
+		return nil, err
+	}
+	warnBypassRLS(ctx, db, logger)
+	return db, nil
+}
+
+// NewMigrationPool создает пул для миграций: без переменных RLS, схема
+// может быть еще пустой или старой.
+func NewMigrationPool(ctx context.Context, cfg config.Postgres, logger *zap.Logger) (*pgxpool.Pool, error) {
+	return newPool(ctx, cfg, logger, nil)
+}
+
+func newPool(ctx context.Context, cfg config.Postgres, logger *zap.Logger, hook func(context.Context, *pgx.Conn) bool) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.Conn)
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.MaxConns = int32(cfg.MaxConns)
+	poolCfg.MinConns = int32(cfg.MinConns)
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.ConnConfig.Tracer = tracing.QueryTracer{}
+	if cfg.StatementTimeout > 0 {
+		poolCfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	poolCfg.BeforeAcquire = hook
+	db, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+	defer cancel()
+	backoff := connectBackoff
+	for {
+		err = db.Ping(ctx)
+		if err == nil {
+			return db, nil
+		}
+		logger.Warn("postgres is unavailable, retrying", zap.Error(err), zap.Duration("backoff", backoff))
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, fmt.Errorf("postgres: %w", err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, connectMaxBackoff)
+	}
+}
+
+// warnBypassRLS предупреждает, если пользователь базы не подчиняется
+// политикам RLS: суперпользователь или роль с BYPASSRLS видят все строки.
+func warnBypassRLS(ctx context.Context, db *pgxpool.Pool, logger *zap.Logger) {
+	query := `select rolsuper or rolbypassrls from pg_roles where rolname = current_user`
+	var bypass bool
+	if err := db.QueryRow(ctx, query).Scan(&bypass); err != nil {
+		logger.Warn("failed to check row level security", zap.Error(err))
+		return
+	}
+	if bypass {
+		logger.Warn("database role bypasses row level security, tender and bid policies are not enforced")
+	}
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	readAttempts = 3
+	readBackoff  = 50 * time.Millisecond
+)
+
+// withRetry повторяет идемпотентное чтение fn при временных ошибках базы.
+// Для изменяющих запросов не используется: повтор после обрыва соединения
+// может применить изменение дважды. Внутри транзакции не повторяет:
+// после ошибки транзакция все равно прервана.
+func withRetry(ctx context.Context, fn func() error) error {
+	if inTx(ctx) {
+		return fn()
+	}
+	backoff := readBackoff
+	var err error
+	for i := 0; i < readAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		err = fn()
+		if err == nil || !isTransient(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"57P01", // admin_shutdown
+			"53300": // too_many_connections
+			return true
+		}
+		// класс 08 — ошибки соединения
+		return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrSearchNotFound = errors.New("сохраненный поиск не найден")
+
+type Search interface {
+	Create(ctx context.Context, userId string, search entities.SavedSearch) (entities.SavedSearch, error)
+	GetByUser(ctx context.Context, userId string) (entities.SavedSearchList, error)
+	Delete(ctx context.Context, id string, userId string) error
+	Match(ctx context.Context, tenderId string) ([]entities.SearchMatch, error)
+	Watch(ctx context.Context, userId string, tenderId string) error
+	Unwatch(ctx context.Context, userId string, tenderId string) error
+	GetWatchers(ctx context.Context, tenderId string) ([]string, error)
+	GetWatched(ctx context.Context, userId string, limit int, offset int) (entities.TenderList, error)
+	Reindex(ctx context.Context) error
+}
+
+type SearchRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchRepo(db *pgxpool.Pool) Search {
+	return &SearchRepo{db: db}
+}
+
+const searchColumns = `id, name, service_type, query, coalesce(budget_min::text, ''),
+	coalesce(budget_max::text, ''), inn, channel, created_at`
+
+func scanSearch(row pgx.Row) (entities.SavedSearch, error) {
+	var (
+		s           entities.SavedSearch
+		serviceType []string
+		channel     string
+	)
+	err := row.Scan(&s.ID, &s.Name, &serviceType, &s.Query, &s.BudgetMin, &s.BudgetMax, &s.INN, &channel, &s.CreatedAt)
+	for _, typ := range serviceType {
+		s.ServiceType = append(s.ServiceType, entities.TenderType(typ))
+	}
+	s.Channel.Scan(channel)
+	return s, err
+}
+
+func (t *SearchRepo) Create(ctx context.Context, userId string, search entities.SavedSearch) (entities.SavedSearch, error) {
+	query := `
+		insert into saved_search(user_id, name, service_type, query, budget_min, budget_max, inn, channel)
+		values (@user_id, @name, @service_type::text[], @query, nullif(@budget_min::text, '')::numeric,
+		nullif(@budget_max::text, '')::numeric, @inn, @channel)
+		returning ` + searchColumns
+	args := filterArgs(search.TenderFilter)
+	args["user_id"], args["name"], args["channel"] = userId, search.Name, string(search.Channel)
+	return scanSearch(conn(ctx, t.db).QueryRow(ctx, query, args))
+}
+
+func (t *SearchRepo) GetByUser(ctx context.Context, userId string) (entities.SavedSearchList, error) {
+	query := `select ` + searchColumns + ` from saved_search where user_id=$1 order by created_at, id`
+	var res entities.SavedSearchList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, userId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			s, err := scanSearch(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, s)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *SearchRepo) Delete(ctx context.Context, id string, userId string) error {
+	query := `delete from saved_search where id=$1 and user_id=$2`
+	tag, err := conn(ctx, t.db).Exec(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSearchNotFound
+	}
+	return nil
+}
+
+// Match возвращает сохраненные поиски, под фильтры которых подходит тендер.
+// Условие то же, что у списка тендеров, только фильтры берутся из saved_search.
+func (t *SearchRepo) Match(ctx context.Context, tenderId string) ([]entities.SearchMatch, error) {
+	query := `
+		select s.id, s.user_id, s.name, s.channel
+		from saved_search s join tender on tender.id=$1
+		where ` + tenderFilterSQL("tender", "s.service_type", "s.query", "s.budget_min", "s.budget_max", "s.inn") + `
+		order by s.created_at, s.id
+	`
+	var res []entities.SearchMatch
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, tenderId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				m       entities.SearchMatch
+				channel string
+			)
+			if err := rows.Scan(&m.SearchID, &m.UserID, &m.Name, &channel); err != nil {
+				return err
+			}
+			m.Channel.Scan(channel)
+			res = append(res, m)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *SearchRepo) Watch(ctx context.Context, userId string, tenderId string) error {
+	query := `insert into tender_watch(user_id, tender_id) values ($1, $2) on conflict do nothing`
+	_, err := conn(ctx, t.db).Exec(ctx, query, userId, tenderId)
+	return err
+}
+
+func (t *SearchRepo) Unwatch(ctx context.Context, userId string, tenderId string) error {
+	query := `delete from tender_watch where user_id=$1 and tender_id=$2`
+	_, err := conn(ctx, t.db).Exec(ctx, query, userId, tenderId)
+	return err
+}
+
+func (t *SearchRepo) GetWatchers(ctx context.Context, tenderId string) ([]string, error) {
+	query := `select user_id from tender_watch where tender_id=$1 order by created_at, user_id`
+	var res []string
+	err := withRetry(ctx, func() error {
+		rows, err := conn(ctx, t.db).Query(ctx, query, tenderId)
+		if err != nil {
+			return err
+		}
+		res, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *SearchRepo) GetWatched(ctx context.Context, userId string, limit int, offset int) (entities.TenderList, error) {
+	// следить можно было, пока тендер был виден; потерявший доступ его не видит
+	query := `
+		select ` + tenderListColumns + ` join tender_watch w on w.tender_id=tender.id
+		where w.user_id=@user_id and ` + tenderVisibleSQL("tender") + `
+		order by w.created_at desc, tender.id limit @limit offset @offset
+	`
+	args := viewerArgs(pgx.NamedArgs{"user_id": userId, "limit": limit, "offset": offset}, entities.Viewer{ID: userId})
+	var res entities.TenderList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, args)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			tend, err := scanTenderListItem(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, tend)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Reindex перестраивает индекс поиска тендеров по словам, не блокируя
+// запись, и обновляет статистику планировщика по tender.
+func (t *SearchRepo) Reindex(ctx context.Context) error {
+	if _, err := t.db.Exec(ctx, `reindex index concurrently tender_search_idx`); err != nil {
+		return err
+	}
+	_, err := t.db.Exec(ctx, `analyze tender`)
+	return err
+}
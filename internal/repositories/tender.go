@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 	"zadanie-6105/internal/delivery/operation"
 	"zadanie-6105/internal/repositories/entities"
 
@@ -11,14 +13,20 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrTenderNotFound — тендера нет или политики RLS скрывают его от сотрудника.
+var ErrTenderNotFound = errors.New("тендер не найден")
+
 type Tender interface {
 	Create(ctx context.Context, tender entities.Tender) (entities.Tender, error)
-	GetTenderList(ctx context.Context, params operation.TenderListParams) (entities.TenderList, error)
-	GetByUser(ctx context.Context, creator string, params operation.TenderListParams) (entities.TenderList, error)
+	GetTenderList(ctx context.Context, params operation.TenderListParams, viewer entities.Viewer) (entities.TenderList, error)
+	GetByUser(ctx context.Context, creator string, params operation.TenderListParams, viewer entities.Viewer) (entities.TenderList, error)
 	ChangeTenderStatus(ctx context.Context, status entities.TenderStatus, id string) (entities.Tender, error)
 	GetTenderStatus(ctx context.Context, id string) (entities.TenderStatus, error)
 	EditTender(ctx context.Context, tender entities.Tender, id string) (entities.Tender, error)
 	CheckTenderOrganization(ctx context.Context, id string) (string, error)
+	Lock(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (entities.Tender, error)
+	ClaimDeadlineReminders(ctx context.Context, within time.Duration) (entities.TenderList, error)
 }
 
 type TenderRepo struct {
@@ -29,14 +37,37 @@ func NewTenderRepo(db *pgxpool.Pool) Tender {
 	return &TenderRepo{db: db}
 }
 
+// tenderListColumns — колонки списков тендеров вместе с краткими данными
+// организации; фильтры ссылаются на таблицу как на tender.
+const tenderListColumns = `tender.id, tender.name, coalesce(tender.description, ''), coalesce(tender.service_type, ''),
+	tender.status, tender.version, tender.created_at, tender.sealed, tender.bid_deadline, tender.reveal_deadline,
+	coalesce(tender.budget::text, ''), coalesce(o.id::text, ''), coalesce(o.name, ''), coalesce(o.type::text, ''),
+	coalesce(o.inn, '')
+	from tender left join organization o on o.id=tender.organization_id`
+
+func scanTenderListItem(row pgx.Row) (entities.Tender, error) {
+	var (
+		tend entities.Tender
+		org  entities.OrganizationSummary
+	)
+	err := row.Scan(&tend.ID, &tend.Name, &tend.Description, &tend.ServiceType, &tend.Status, &tend.Version,
+		&tend.CreatedAt, &tend.Sealed, &tend.BidDeadline, &tend.RevealDeadline, &tend.Budget,
+		&org.ID, &org.Name, &org.Type, &org.INN)
+	if org.ID != "" {
+		tend.Organization = &org
+	}
+	return tend, err
+}
+
 func (t *TenderRepo) Create(ctx context.Context, tender entities.Tender) (entities.Tender, error) {
 	query := `
-		insert into tender(name, description, service_type, status, organization_id, creator_username, version, created_at) 
-		values ($1, $2, $3, $4, $5, $6, 1, now()) 
-		returning id, name, description, service_type, status, version, created_at
+		insert into tender(name, description, service_type, status, organization_id, creator_username, version, created_at,
+		sealed, bid_deadline, reveal_deadline, budget)
+		values ($1, $2, $3, $4, $5, $6, 1, now(), $7, $8, $9, nullif($10, '')::numeric)
+		returning id, name, description, service_type, status, version, created_at, sealed, bid_deadline, reveal_deadline, coalesce(budget::text, '')
 	`
 	var res entities.Tender
-	row := t.db.QueryRow(
+	row := conn(ctx, t.db).QueryRow(
 		ctx, query,
 		tender.Name,
 		tender.Description,
@@ -44,72 +75,91 @@ func (t *TenderRepo) Create(ctx context.Context, tender entities.Tender) (entiti
 		entities.TenderStatusCreated,
 		tender.OrganizationID,
 		tender.CreatorUsername,
+		tender.Sealed,
+		tender.BidDeadline,
+		tender.RevealDeadline,
+		tender.Budget,
 	)
 	err := row.Scan(&res.ID, &res.Name, &res.Description,
-		&res.ServiceType, &res.Status, &res.Version, &res.CreatedAt)
+		&res.ServiceType, &res.Status, &res.Version, &res.CreatedAt,
+		&res.Sealed, &res.BidDeadline, &res.RevealDeadline, &res.Budget)
 	if err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
-func (t *TenderRepo) GetTenderList(ctx context.Context, params operation.TenderListParams) (entities.TenderList, error) {
+func (t *TenderRepo) GetTenderList(ctx context.Context, params operation.TenderListParams, viewer entities.Viewer) (entities.TenderList, error) {
 	var (
 		res entities.TenderList
 		sb  strings.Builder
 	)
 	sb.WriteString(
-		`select id, name, description, service_type, status, version, created_at
-		from tender order by name ASC`)
+		`select ` + tenderListColumns + ` where ` + tenderVisibleSQL("tender") + ` and `)
+	sb.WriteString(tenderFilterSQL("tender", "@service_type::text[]", "@query::text", "nullif(@budget_min::text, '')::numeric",
+		"nullif(@budget_max::text, '')::numeric", "@inn::text"))
+	namedArgs := viewerArgs(filterArgs(params.TenderFilter), viewer)
+	sb.WriteString(queryWordsSQL("tender", params.Query, namedArgs))
+	sb.WriteString(` order by tender.name ASC`)
 	queryFilters, args := t.inQuery(params)
 	sb.WriteString(queryFilters)
-	namedArgs := pgx.NamedArgs{
-		"limit":  args["limit"],
-		"offset": args["offset"],
-	}
-	rows, err := t.db.Query(ctx, sb.String(), namedArgs)
-	if err != nil {
-		return entities.TenderList{}, err
-	}
-	for rows.Next() {
-		var tend entities.Tender
-		err := rows.Scan(&tend.ID, &tend.Name, &tend.Description,
-			&tend.ServiceType, &tend.Status, &tend.Version, &tend.CreatedAt)
+	namedArgs["limit"], namedArgs["offset"] = args["limit"], args["offset"]
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, sb.String(), namedArgs)
 		if err != nil {
-			return entities.TenderList{}, err
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			tend, err := scanTenderListItem(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, tend)
 		}
-		res = append(res, tend)
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.TenderList{}, err
 	}
 	return res, nil
 }
 
-func (t *TenderRepo) GetByUser(ctx context.Context, creator string, params operation.TenderListParams) (entities.TenderList, error) {
+func (t *TenderRepo) GetByUser(ctx context.Context, creator string, params operation.TenderListParams,
+	viewer entities.Viewer) (entities.TenderList, error) {
 	var (
 		res entities.TenderList
 		sb  strings.Builder
 	)
 	sb.WriteString(
-		`select id, name, description, service_type, status, version, created_at
-		from tender where creator_username=@creator`)
+		`select ` + tenderListColumns + ` where creator_username=@creator and ` + tenderVisibleSQL("tender") + ` and `)
+	sb.WriteString(tenderFilterSQL("tender", "@service_type::text[]", "@query::text", "nullif(@budget_min::text, '')::numeric",
+		"nullif(@budget_max::text, '')::numeric", "@inn::text"))
+	namedArgs := viewerArgs(filterArgs(params.TenderFilter), viewer)
+	sb.WriteString(queryWordsSQL("tender", params.Query, namedArgs))
+	sb.WriteString(` order by tender.name ASC`)
 	queryFilters, args := t.inQuery(params)
 	sb.WriteString(queryFilters)
-	namedArgs := pgx.NamedArgs{
-		"limit":   args["limit"],
-		"offset":  args["offset"],
-		"creator": creator,
-	}
-	rows, err := t.db.Query(ctx, sb.String(), namedArgs)
-	if err != nil {
-		return entities.TenderList{}, err
-	}
-	for rows.Next() {
-		var tend entities.Tender
-		err := rows.Scan(&tend.ID, &tend.Name, &tend.Description,
-			&tend.ServiceType, &tend.Status, &tend.Version, &tend.CreatedAt)
+	namedArgs["limit"], namedArgs["offset"], namedArgs["creator"] = args["limit"], args["offset"], creator
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, sb.String(), namedArgs)
 		if err != nil {
-			return entities.TenderList{}, err
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			tend, err := scanTenderListItem(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, tend)
 		}
-		res = append(res, tend)
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.TenderList{}, err
 	}
 	return res, nil
 }
@@ -117,12 +167,13 @@ func (t *TenderRepo) GetByUser(ctx context.Context, creator string, params opera
 func (t *TenderRepo) ChangeTenderStatus(ctx context.Context, status entities.TenderStatus, id string) (entities.Tender, error) {
 	query := `
 		update tender set status=$1, updated_at=now() where id=$2
-		returning id, name, description, service_type, status, version, created_at
+		returning id, name, description, service_type, status, version, created_at, sealed, bid_deadline, reveal_deadline, coalesce(budget::text, '')
 	`
 	var res entities.Tender
-	row := t.db.QueryRow(ctx, query, status, id)
+	row := conn(ctx, t.db).QueryRow(ctx, query, status, id)
 	err := row.Scan(&res.ID, &res.Name, &res.Description,
-		&res.ServiceType, &res.Status, &res.Version, &res.CreatedAt)
+		&res.ServiceType, &res.Status, &res.Version, &res.CreatedAt,
+		&res.Sealed, &res.BidDeadline, &res.RevealDeadline, &res.Budget)
 	if err != nil {
 		return res, err
 	}
@@ -132,7 +183,9 @@ func (t *TenderRepo) ChangeTenderStatus(ctx context.Context, status entities.Ten
 func (t *TenderRepo) GetTenderStatus(ctx context.Context, id string) (entities.TenderStatus, error) {
 	query := `select status from tender where id=$1`
 	var res entities.TenderStatus
-	err := t.db.QueryRow(ctx, query, id).Scan(&res)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res)
+	})
 	if err != nil {
 		return res, err
 	}
@@ -148,29 +201,100 @@ func (t *TenderRepo) EditTender(ctx context.Context, tender entities.Tender, id
 		`update tender set version=version+1`)
 	queryFilters, args := t.EditQuery(tender)
 	sb.WriteString(queryFilters)
-	sb.WriteString(` where id=@id returning id, name, description, service_type, status, version, created_at`)
+	sb.WriteString(` where id=@id returning id, name, description, service_type, status, version, created_at,
+		sealed, bid_deadline, reveal_deadline, coalesce(budget::text, '')`)
 	namedArgs := pgx.NamedArgs{
 		"name":        args["name"],
 		"description": args["description"],
 		"service":     args["service"],
+		"budget":      args["budget"],
 		"id":          id,
 	}
-	row := t.db.QueryRow(ctx, sb.String(), namedArgs)
+	row := conn(ctx, t.db).QueryRow(ctx, sb.String(), namedArgs)
 	err := row.Scan(&res.ID, &res.Name, &res.Description,
-		&res.ServiceType, &res.Status, &res.Version, &res.CreatedAt)
+		&res.ServiceType, &res.Status, &res.Version, &res.CreatedAt,
+		&res.Sealed, &res.BidDeadline, &res.RevealDeadline, &res.Budget)
+	if err != nil {
+		return entities.Tender{}, err
+	}
+	return res, nil
+}
+
+func (t *TenderRepo) GetByID(ctx context.Context, id string) (entities.Tender, error) {
+	query := `
+		select id, name, coalesce(description, ''), coalesce(service_type, ''), status,
+		coalesce(organization_id::text, ''), coalesce(creator_username, ''), version, created_at,
+		sealed, bid_deadline, reveal_deadline, coalesce(budget::text, '')
+		from tender where id=$1
+	`
+	var res entities.Tender
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res.ID, &res.Name, &res.Description, &res.ServiceType,
+			&res.Status, &res.OrganizationID, &res.CreatorUsername, &res.Version, &res.CreatedAt,
+			&res.Sealed, &res.BidDeadline, &res.RevealDeadline, &res.Budget)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.Tender{}, ErrTenderNotFound
+	}
 	if err != nil {
 		return entities.Tender{}, err
 	}
 	return res, nil
 }
 
+// ClaimDeadlineReminders отмечает и возвращает опубликованные закрытые
+// тендеры, до bid_deadline которых осталось меньше within. Каждый тендер
+// возвращается один раз.
+func (t *TenderRepo) ClaimDeadlineReminders(ctx context.Context, within time.Duration) (entities.TenderList, error) {
+	query := `
+		update tender set bid_deadline_reminded_at=now()
+		where sealed and status='published' and bid_deadline_reminded_at is null
+		and bid_deadline > now() and bid_deadline <= now() + make_interval(secs => $1::float8)
+		returning id, name, coalesce(description, ''), coalesce(service_type, ''), status,
+		coalesce(organization_id::text, ''), coalesce(creator_username, ''), version, created_at,
+		sealed, bid_deadline, reveal_deadline, coalesce(budget::text, '')
+	`
+	var res entities.TenderList
+	rows, err := conn(ctx, t.db).Query(ctx, query, within.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tender entities.Tender
+		err := rows.Scan(&tender.ID, &tender.Name, &tender.Description, &tender.ServiceType,
+			&tender.Status, &tender.OrganizationID, &tender.CreatorUsername, &tender.Version, &tender.CreatedAt,
+			&tender.Sealed, &tender.BidDeadline, &tender.RevealDeadline, &tender.Budget)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, tender)
+	}
+	return res, rows.Err()
+}
+
+// Lock блокирует тендер до конца транзакции, чтобы решения по его
+// предложениям принимались по очереди.
+func (t *TenderRepo) Lock(ctx context.Context, id string) error {
+	query := `select id from tender where id=$1 for update`
+	err := conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTenderNotFound
+	}
+	return err
+}
+
+// CheckTenderOrganization возвращает организацию тендера или
+// ErrTenderNotFound.
 func (t *TenderRepo) CheckTenderOrganization(ctx context.Context, id string) (string, error) {
 	query := `select organization_id from tender where id=$1`
 	var res string
-	err := t.db.QueryRow(ctx, query, id).Scan(&res)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, id).Scan(&res)
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", nil
+			return "", ErrTenderNotFound
 		}
 		return "", err
 	}
@@ -192,6 +316,10 @@ func (t *TenderRepo) EditQuery(params entities.Tender) (string, map[string]any)
 		sb.WriteString(`, service_type=@service`)
 		args["service"] = params.ServiceType
 	}
+	if params.Budget != "" {
+		sb.WriteString(`, budget=@budget::numeric`)
+		args["budget"] = params.Budget
+	}
 	return sb.String(), args
 }
 
@@ -213,3 +341,47 @@ func (t *TenderRepo) inQuery(params operation.TenderListParams) (string, map[str
 	}
 	return sb.String(), args
 }
+
+// tenderFilterSQL — условие на тендер t по фильтрам entities.TenderFilter.
+// Фильтры передаются SQL-выражениями, поэтому одно и то же условие
+// применяется к параметрам списка тендеров и к колонкам saved_search.
+func tenderFilterSQL(t, serviceType, query, budgetMin, budgetMax, inn string) string {
+	return fmt.Sprintf(`(cardinality(%[2]s) = 0 or %[1]s.service_type = any(%[2]s))
+		and not exists (
+			select 1 from regexp_split_to_table(lower(%[3]s), '\s+') w
+			where w <> '' and strpos(lower(%[1]s.name || ' ' || coalesce(%[1]s.description, '')), w) = 0
+		)
+		and (%[4]s is null or %[1]s.budget >= %[4]s)
+		and (%[5]s is null or %[1]s.budget <= %[5]s)
+		and (%[6]s = '' or exists (
+			select 1 from organization fo where fo.id = %[1]s.organization_id and fo.inn = %[6]s
+		))`, t, serviceType, query, budgetMin, budgetMax, inn)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// queryWordsSQL дублирует условие на слова query из tenderFilterSQL в виде,
+// по которому работает триграммный индекс tender_search_idx (миграция 0017).
+func queryWordsSQL(t string, query string, args pgx.NamedArgs) string {
+	var sb strings.Builder
+	for i, w := range strings.Fields(strings.ToLower(query)) {
+		name := fmt.Sprintf("word%d", i)
+		fmt.Fprintf(&sb, ` and lower(%s.name || ' ' || coalesce(%[1]s.description, '')) like @%s`, t, name)
+		args[name] = "%" + likeEscaper.Replace(w) + "%"
+	}
+	return sb.String()
+}
+
+func filterArgs(f entities.TenderFilter) pgx.NamedArgs {
+	serviceType := make([]string, 0, len(f.ServiceType))
+	for _, s := range f.ServiceType {
+		serviceType = append(serviceType, string(s))
+	}
+	return pgx.NamedArgs{
+		"service_type": serviceType,
+		"query":        f.Query,
+		"budget_min":   f.BudgetMin,
+		"budget_max":   f.BudgetMax,
+		"inn":          f.INN,
+	}
+}
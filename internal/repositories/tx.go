@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TxManager выполняет несколько вызовов репозиториев в одной транзакции.
+// Транзакция передается через ctx, поэтому сигнатуры репозиториев не меняются.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	db *pgxpool.Pool
+}
+
+func NewTxManager(db *pgxpool.Pool) TxManager {
+	return &txManager{db: db}
+}
+
+type txKey struct{}
+
+// txState — открытая транзакция и сотрудник, от имени которого сейчас
+// выставлены переменные RLS в ней.
+type txState struct {
+	tx     pgx.Tx
+	viewer entities.Viewer
+}
+
+// WithinTx открывает транзакцию и фиксирует ее, если fn вернула nil.
+// Вложенный вызов присоединяется к уже открытой транзакции.
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTx(ctx) {
+		return fn(ctx)
+	}
+	return pgx.BeginFunc(ctx, m.db, func(tx pgx.Tx) error {
+		// переменные сессии выставил пул по viewer из ctx
+		state := &txState{tx: tx, viewer: viewerFrom(ctx)}
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+}
+
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// conn возвращает транзакцию из ctx, если она открыта, иначе пул.
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return txConn{state}
+	}
+	return db
+}
+
+// txConn перед запросом переключает переменные RLS транзакции на viewer из
+// ctx запроса (set_config(..., true)), если он отличается от текущего: так
+// System и WithViewer действуют и внутри WithinTx.
+type txConn struct {
+	*txState
+}
+
+func (c txConn) use(ctx context.Context) error {
+	viewer := viewerFrom(ctx)
+	if viewer == c.viewer {
+		return nil
+	}
+	if _, err := c.tx.Exec(ctx, sessionQuery, sessionArgs(viewer, true)...); err != nil {
+		return err
+	}
+	c.viewer = viewer
+	return nil
+}
+
+func (c txConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := c.use(ctx); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return c.tx.Exec(ctx, sql, args...)
+}
+
+func (c txConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := c.use(ctx); err != nil {
+		return nil, err
+	}
+	return c.tx.Query(ctx, sql, args...)
+}
+
+func (c txConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := c.use(ctx); err != nil {
+		return errRow{err}
+	}
+	return c.tx.QueryRow(ctx, sql, args...)
+}
+
+func (c txConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if err := c.use(ctx); err != nil {
+		return errBatch{err}
+	}
+	return c.tx.SendBatch(ctx, b)
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(dest ...any) error { return r.err }
+
+type errBatch struct{ err error }
+
+func (b errBatch) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, b.err }
+func (b errBatch) Query() (pgx.Rows, error)         { return nil, b.err }
+func (b errBatch) QueryRow() pgx.Row                { return errRow{b.err} }
+func (b errBatch) Close() error                     { return b.err }
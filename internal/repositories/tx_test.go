@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// recordingTx запоминает запросы транзакции и аргументы set_config.
+type recordingTx struct {
+	pgx.Tx
+	queries  []string
+	sessions [][]any
+}
+
+func (tx *recordingTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if sql == sessionQuery {
+		tx.sessions = append(tx.sessions, args)
+	} else {
+		tx.queries = append(tx.queries, sql)
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func TestTxConnSwitchesViewer(t *testing.T) {
+	employee := entities.Viewer{ID: "e1"}
+	tx := &recordingTx{}
+	c := conn(context.WithValue(context.Background(), txKey{}, &txState{tx: tx, viewer: employee}), nil)
+	outer := WithViewer(context.Background(), employee)
+
+	for _, ctx := range []context.Context{outer, System(outer), System(outer), outer} {
+		if _, err := c.Exec(ctx, "select 1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(tx.queries) != 4 {
+		t.Fatalf("executed %d queries, want 4", len(tx.queries))
+	}
+	if len(tx.sessions) != 2 {
+		t.Fatalf("set_config called %d times, want 2: %v", len(tx.sessions), tx.sessions)
+	}
+	if tx.sessions[0][0] != "on" || tx.sessions[1][0] != "off" || tx.sessions[1][1] != "e1" {
+		t.Fatalf("unexpected sessions %v", tx.sessions)
+	}
+	for _, args := range tx.sessions {
+		if args[3] != true {
+			t.Fatalf("set_config is not local to the transaction: %v", args)
+		}
+	}
+}
@@ -3,14 +3,28 @@ package repositories
 import (
 	"context"
 	"errors"
+	"strings"
+	"zadanie-6105/internal/repositories/entities"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrUserNotFound  = errors.New("пользователь не существует")
+	ErrUsernameTaken = errors.New("логин уже занят")
+)
+
 type User interface {
 	GetUserIDByUsername(ctx context.Context, name string) (string, error)
 	IsResponsible(ctx context.Context, name string) (string, error)
+	GetRole(ctx context.Context, name string, organizationId string) (entities.OrganizationRole, error)
+	GetRoles(ctx context.Context, userId string) (map[string]entities.OrganizationRole, error)
+	IsAdmin(ctx context.Context, name string) (bool, error)
+	Create(ctx context.Context, employee entities.Employee) (entities.Employee, error)
+	GetByID(ctx context.Context, id string) (entities.Employee, error)
+	Edit(ctx context.Context, employee entities.Employee, id string) (entities.Employee, error)
 }
 
 type UserRepo struct {
@@ -24,8 +38,13 @@ func NewUserRepo(db *pgxpool.Pool) User {
 func (t *UserRepo) GetUserIDByUsername(ctx context.Context, name string) (string, error) {
 	query := `select id from employee where username=$1`
 	var id string
-	err := t.db.QueryRow(ctx, query, name).Scan(&id)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, name).Scan(&id)
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
 		return "", err
 	}
 	return id, nil
@@ -35,7 +54,9 @@ func (t *UserRepo) IsResponsible(ctx context.Context, name string) (string, erro
 	query := `select organization_id from organization_responsible o JOIN employee e
 	on o.user_id=e.id where username=$1`
 	var id string
-	err := t.db.QueryRow(ctx, query, name).Scan(&id)
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, name).Scan(&id)
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return "", nil
@@ -44,3 +65,143 @@ func (t *UserRepo) IsResponsible(ctx context.Context, name string) (string, erro
 	}
 	return id, nil
 }
+
+// GetRole — роль сотрудника в организации; "" — сотрудник не ответственен
+// за нее. В отличие от IsResponsible учитывает все организации сотрудника.
+func (t *UserRepo) GetRole(ctx context.Context, name string, organizationId string) (entities.OrganizationRole, error) {
+	query := `select o.role from organization_responsible o JOIN employee e
+	on o.user_id=e.id where username=$1 and o.organization_id=$2`
+	var str string
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, name, organizationId).Scan(&str)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	var res entities.OrganizationRole
+	res.Scan(str)
+	return res, nil
+}
+
+// GetRoles — роли сотрудника во всех его организациях по id организации.
+func (t *UserRepo) GetRoles(ctx context.Context, userId string) (map[string]entities.OrganizationRole, error) {
+	query := `select organization_id, role from organization_responsible where user_id=$1`
+	var res map[string]entities.OrganizationRole
+	err := withRetry(ctx, func() error {
+		res = make(map[string]entities.OrganizationRole)
+		rows, err := conn(ctx, t.db).Query(ctx, query, userId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				id   string
+				str  string
+				role entities.OrganizationRole
+			)
+			if err := rows.Scan(&id, &str); err != nil {
+				return err
+			}
+			role.Scan(str)
+			res[id] = role
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *UserRepo) IsAdmin(ctx context.Context, name string) (bool, error) {
+	query := `select is_admin from employee where username=$1`
+	var res bool
+	err := withRetry(ctx, func() error {
+		return conn(ctx, t.db).QueryRow(ctx, query, name).Scan(&res)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrUserNotFound
+		}
+		return false, err
+	}
+	return res, nil
+}
+
+func (t *UserRepo) Create(ctx context.Context, employee entities.Employee) (entities.Employee, error) {
+	query := `
+		insert into employee(username, first_name, last_name, email, locale, is_admin)
+		values ($1, $2, $3, nullif($4, ''), coalesce(nullif($5, ''), 'ru'), $6)
+		returning id, username, coalesce(first_name, ''), coalesce(last_name, ''),
+		coalesce(email, ''), locale, is_admin, created_at
+	`
+	var res entities.Employee
+	row := conn(ctx, t.db).QueryRow(ctx, query, employee.Username, employee.FirstName, employee.LastName,
+		employee.Email, employee.Locale, employee.IsAdmin)
+	err := row.Scan(&res.ID, &res.Username, &res.FirstName, &res.LastName, &res.Email, &res.Locale,
+		&res.IsAdmin, &res.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return res, ErrUsernameTaken
+		}
+		return res, err
+	}
+	return res, nil
+}
+
+const employeeColumns = `id, username, coalesce(first_name, ''), coalesce(last_name, ''),
+	coalesce(email, ''), locale, is_admin, created_at`
+
+func scanEmployee(row pgx.Row) (entities.Employee, error) {
+	var res entities.Employee
+	err := row.Scan(&res.ID, &res.Username, &res.FirstName, &res.LastName, &res.Email, &res.Locale,
+		&res.IsAdmin, &res.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, ErrUserNotFound
+	}
+	return res, err
+}
+
+func (t *UserRepo) GetByID(ctx context.Context, id string) (entities.Employee, error) {
+	query := `select ` + employeeColumns + ` from employee where id=$1`
+	var res entities.Employee
+	err := withRetry(ctx, func() error {
+		var err error
+		res, err = scanEmployee(conn(ctx, t.db).QueryRow(ctx, query, id))
+		return err
+	})
+	if err != nil {
+		return entities.Employee{}, err
+	}
+	return res, nil
+}
+
+// Edit меняет только непустые поля employee; логин не меняется.
+func (t *UserRepo) Edit(ctx context.Context, employee entities.Employee, id string) (entities.Employee, error) {
+	var sb strings.Builder
+	sb.WriteString(`update employee set updated_at=now()`)
+	args := pgx.NamedArgs{"id": id}
+	if employee.FirstName != "" {
+		sb.WriteString(`, first_name=@first_name`)
+		args["first_name"] = employee.FirstName
+	}
+	if employee.LastName != "" {
+		sb.WriteString(`, last_name=@last_name`)
+		args["last_name"] = employee.LastName
+	}
+	if employee.Email != "" {
+		sb.WriteString(`, email=@email`)
+		args["email"] = employee.Email
+	}
+	if employee.Locale != "" {
+		sb.WriteString(`, locale=@locale`)
+		args["locale"] = employee.Locale
+	}
+	sb.WriteString(` where id=@id returning ` + employeeColumns)
+	return scanEmployee(conn(ctx, t.db).QueryRow(ctx, sb.String(), args))
+}
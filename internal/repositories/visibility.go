@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// tenderVisibleSQL — условие видимости тендера t: опубликованные и закрытые
+// видны всем, неопубликованные — сотрудникам его организации с
+// PermTenderView. Параметры задает viewerArgs.
+func tenderVisibleSQL(t string) string {
+	return fmt.Sprintf(`(@view_all::bool or %[1]s.status <> 'created' or exists (
+			select 1 from organization_responsible vr
+			where vr.organization_id = %[1]s.organization_id and vr.user_id::text = @viewer_id::text
+			and vr.role = any(@view_roles::text[])
+		))`, t)
+}
+
+// bidVisibleSQL — условие видимости предложения b: его видят автор,
+// сотрудники организаций автора, если предложение от организации, и
+// ответственные организации тендера с PermTenderView, когда оно подано.
+func bidVisibleSQL(b string) string {
+	return fmt.Sprintf(`(@view_all::bool or %[1]s.author_id::text = @viewer_id::text
+		or (%[1]s.author_type = 'organization' and exists (
+			select 1 from organization_responsible va
+			join organization_responsible vv on vv.organization_id = va.organization_id
+			where va.user_id = %[1]s.author_id and vv.user_id::text = @viewer_id::text
+		))
+		or (%[1]s.status = any(@submitted::text[]) and exists (
+			select 1 from tender vt
+			join organization_responsible vr on vr.organization_id = vt.organization_id
+			where vt.id = %[1]s.tender_id and vr.user_id::text = @viewer_id::text
+			and vr.role = any(@view_roles::text[])
+		)))`, b)
+}
+
+func viewerArgs(args pgx.NamedArgs, viewer entities.Viewer) pgx.NamedArgs {
+	roles := viewRoles()
+	submitted := []string{}
+	for _, s := range entities.SubmittedBidStatuses {
+		submitted = append(submitted, string(s))
+	}
+	args["view_all"], args["viewer_id"] = viewer.All, viewer.ID
+	args["view_roles"], args["submitted"] = roles, submitted
+	return args
+}
+
+func viewRoles() []string {
+	roles := []string{}
+	for _, r := range entities.RolesWith(entities.PermTenderView) {
+		roles = append(roles, string(r))
+	}
+	return roles
+}
+
+type viewerKey struct{}
+
+// WithViewer задает, от чьего имени запросы с ctx обращаются к базе: по нему
+// выставляются переменные app.* для политик RLS на tender и bid (миграции
+// 0016 и 0020). Без WithViewer и System запросы идут от анонима и видят
+// только опубликованные и закрытые тендеры.
+func WithViewer(ctx context.Context, viewer entities.Viewer) context.Context {
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+// System снимает ограничения RLS: для фоновых задач, tenderctl и проверок,
+// которые должны учитывать скрытые от сотрудника строки. Действует и внутри
+// транзакции, но только на запросы с этим ctx.
+func System(ctx context.Context) context.Context {
+	return WithViewer(ctx, entities.Viewer{All: true})
+}
+
+func viewerFrom(ctx context.Context) entities.Viewer {
+	viewer, _ := ctx.Value(viewerKey{}).(entities.Viewer)
+	return viewer
+}
+
+// sessionQuery выставляет переменные RLS; $4 — только до конца транзакции.
+// Таблиц не читает, поэтому работает на базе с любой версией схемы.
+const sessionQuery = `select set_config('app.bypass', $1, $4), set_config('app.employee_id', $2, $4),
+	set_config('app.view_roles', $3, $4)`
+
+func sessionArgs(viewer entities.Viewer, local bool) []any {
+	bypass := "off"
+	if viewer.All {
+		bypass = "on"
+	}
+	return []any{bypass, viewer.ID, strings.Join(viewRoles(), ","), local}
+}
+
+// sessionHook — BeforeAcquire пула. Переменные перезаписываются при каждой
+// выдаче соединения, поэтому в AfterRelease их сбрасывать не нужно. Если
+// выставить их не удалось, соединение закрывается, а пул берет другое.
+func sessionHook(logger *zap.Logger) func(ctx context.Context, c *pgx.Conn) bool {
+	return func(ctx context.Context, c *pgx.Conn) bool {
+		if _, err := c.Exec(ctx, sessionQuery, sessionArgs(viewerFrom(ctx), false)...); err != nil {
+			logger.Error("failed to set row level security session", zap.Error(err))
+			return false
+		}
+		return true
+	}
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// hiddenTender переводит тендер из testTender в статус created: такой
+// тендер видят только сотрудники его организации. Возвращает id организации.
+func hiddenTender(t *testing.T, db querier, tenderId string) string {
+	t.Helper()
+	var organizationId string
+	err := db.QueryRow(System(context.Background()),
+		`update tender set status = 'created' where id = $1 returning organization_id`, tenderId).Scan(&organizationId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return organizationId
+}
+
+func TestRowLevelSecurityFailsClosed(t *testing.T) {
+	db := testPool(t)
+	employeeId, tenderId := testTender(t, db)
+	hiddenTender(t, db, tenderId)
+	repo := NewTenderRepo(db)
+
+	if _, err := repo.GetByID(context.Background(), tenderId); !errors.Is(err, ErrTenderNotFound) {
+		t.Fatalf("without viewer: got %v, want %v", err, ErrTenderNotFound)
+	}
+	if _, err := repo.GetByID(System(context.Background()), tenderId); err != nil {
+		t.Fatalf("System: %v", err)
+	}
+	ctx := WithViewer(context.Background(), entities.Viewer{ID: employeeId})
+	if _, err := repo.GetByID(ctx, tenderId); err != nil {
+		t.Fatalf("responsible: %v", err)
+	}
+}
+
+func TestSystemWithinTx(t *testing.T) {
+	db := testPool(t)
+	_, tenderId := testTender(t, db)
+	organizationId := hiddenTender(t, db, tenderId)
+	tenders, orgs, bids := NewTenderRepo(db), NewOrganizationRepo(db), NewBidRepo(db)
+	outsider := WithViewer(context.Background(), entities.Viewer{ID: "00000000-0000-0000-0000-000000000000"})
+
+	err := NewTxManager(db).WithinTx(outsider, func(ctx context.Context) error {
+		has, err := orgs.HasTenders(ctx, organizationId)
+		if err != nil {
+			return err
+		}
+		if !has {
+			t.Error("HasTenders does not see the hidden tender inside a transaction")
+		}
+		if _, err := bids.CountByTender(ctx, tenderId); err != nil {
+			return err
+		}
+		// после System запросы транзакции снова идут от сотрудника
+		if _, err := tenders.GetByID(ctx, tenderId); !errors.Is(err, ErrTenderNotFound) {
+			t.Errorf("outsider after System: got %v, want %v", err, ErrTenderNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("вебхук не найден")
+	ErrDeliveryNotFound = errors.New("доставка не найдена")
+	// ErrDeliveryExists — доставка этого события вебхуку уже создана.
+	ErrDeliveryExists = errors.New("доставка события уже создана")
+)
+
+type Webhook interface {
+	Create(ctx context.Context, hook entities.Webhook) (entities.Webhook, error)
+	GetByOrganization(ctx context.Context, organizationId string) (entities.WebhookList, error)
+	GetByID(ctx context.Context, id string) (entities.Webhook, error)
+	Delete(ctx context.Context, id string, organizationId string) error
+	GetSubscribed(ctx context.Context, organizationId string, event entities.EventType) (entities.WebhookList, error)
+	CreateDelivery(ctx context.Context, delivery entities.WebhookDelivery) (entities.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id string) (entities.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, webhookId string, limit int, offset int) (entities.WebhookDeliveryList, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (entities.WebhookDeliveryList, error)
+	CompleteDelivery(ctx context.Context, id string, code int) error
+	FailDelivery(ctx context.Context, id string, code int, reason string, retryIn time.Duration) error
+}
+
+type WebhookRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepo(db *pgxpool.Pool) Webhook {
+	return &WebhookRepo{db: db}
+}
+
+const webhookColumns = `id, organization_id, url, events, active, created_at`
+
+func scanWebhook(row pgx.Row) (entities.Webhook, error) {
+	var (
+		res    entities.Webhook
+		events []string
+	)
+	err := row.Scan(&res.ID, &res.OrganizationID, &res.URL, &events, &res.Active, &res.CreatedAt)
+	res.Events = make([]entities.EventType, 0, len(events))
+	for _, e := range events {
+		res.Events = append(res.Events, entities.EventType(e))
+	}
+	return res, err
+}
+
+func (t *WebhookRepo) Create(ctx context.Context, hook entities.Webhook) (entities.Webhook, error) {
+	query := `
+		insert into webhook(organization_id, url, secret, events) values ($1, $2, $3, $4)
+		returning ` + webhookColumns
+	events := make([]string, 0, len(hook.Events))
+	for _, e := range hook.Events {
+		events = append(events, string(e))
+	}
+	res, err := scanWebhook(conn(ctx, t.db).QueryRow(ctx, query, hook.OrganizationID, hook.URL, hook.Secret, events))
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+	return res, nil
+}
+
+func (t *WebhookRepo) GetByOrganization(ctx context.Context, organizationId string) (entities.WebhookList, error) {
+	query := `select ` + webhookColumns + ` from webhook where organization_id=$1 and active order by created_at`
+	return t.list(ctx, query, organizationId)
+}
+
+func (t *WebhookRepo) GetSubscribed(ctx context.Context, organizationId string, event entities.EventType) (entities.WebhookList, error) {
+	query := `
+		select ` + webhookColumns + ` from webhook
+		where organization_id=$1 and active and (cardinality(events) = 0 or $2 = any(events))
+	`
+	return t.list(ctx, query, organizationId, string(event))
+}
+
+func (t *WebhookRepo) list(ctx context.Context, query string, args ...any) (entities.WebhookList, error) {
+	var res entities.WebhookList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			hook, err := scanWebhook(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, hook)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.WebhookList{}, err
+	}
+	return res, nil
+}
+
+func (t *WebhookRepo) GetByID(ctx context.Context, id string) (entities.Webhook, error) {
+	query := `select ` + webhookColumns + ` from webhook where id=$1`
+	var res entities.Webhook
+	err := withRetry(ctx, func() error {
+		var err error
+		res, err = scanWebhook(conn(ctx, t.db).QueryRow(ctx, query, id))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.Webhook{}, ErrWebhookNotFound
+		}
+		return entities.Webhook{}, err
+	}
+	return res, nil
+}
+
+// Delete отключает вебхук; журнал доставок сохраняется.
+func (t *WebhookRepo) Delete(ctx context.Context, id string, organizationId string) error {
+	query := `update webhook set active=false where id=$1 and organization_id=$2 and active`
+	tag, err := conn(ctx, t.db).Exec(ctx, query, id, organizationId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+const deliveryColumns = `
+	d.id, d.webhook_id, d.event, coalesce(d.event_id, ''), d.payload, d.status, d.attempts, coalesce(d.response_code, 0),
+	coalesce(d.last_error, ''), d.next_attempt_at, d.created_at, d.delivered_at
+`
+
+func scanDelivery(row pgx.Row, dest ...any) (entities.WebhookDelivery, error) {
+	var res entities.WebhookDelivery
+	err := row.Scan(append([]any{&res.ID, &res.WebhookID, &res.Event, &res.EventID, &res.Payload, &res.Status, &res.Attempts,
+		&res.ResponseCode, &res.LastError, &res.NextAttemptAt, &res.CreatedAt, &res.DeliveredAt}, dest...)...)
+	return res, err
+}
+
+// CreateDelivery ставит доставку в очередь. Если доставка события с
+// delivery.EventID этому вебхуку уже есть, возвращает ErrDeliveryExists.
+func (t *WebhookRepo) CreateDelivery(ctx context.Context, delivery entities.WebhookDelivery) (entities.WebhookDelivery, error) {
+	query := `
+		with d as (
+			insert into webhook_delivery(webhook_id, event, event_id, payload) values ($1, $2, nullif($3, ''), $4)
+			on conflict (webhook_id, event_id) where event_id is not null do nothing
+			returning *
+		)
+		select ` + deliveryColumns + ` from d
+	`
+	res, err := scanDelivery(conn(ctx, t.db).QueryRow(ctx, query,
+		delivery.WebhookID, delivery.Event, delivery.EventID, delivery.Payload))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.WebhookDelivery{}, ErrDeliveryExists
+	}
+	if err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	return res, nil
+}
+
+func (t *WebhookRepo) GetDelivery(ctx context.Context, id string) (entities.WebhookDelivery, error) {
+	query := `select ` + deliveryColumns + ` from webhook_delivery d where d.id=$1`
+	var res entities.WebhookDelivery
+	err := withRetry(ctx, func() error {
+		var err error
+		res, err = scanDelivery(conn(ctx, t.db).QueryRow(ctx, query, id))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.WebhookDelivery{}, ErrDeliveryNotFound
+		}
+		return entities.WebhookDelivery{}, err
+	}
+	return res, nil
+}
+
+func (t *WebhookRepo) GetDeliveries(ctx context.Context, webhookId string, limit int, offset int) (entities.WebhookDeliveryList, error) {
+	query := `
+		select ` + deliveryColumns + ` from webhook_delivery d
+		where d.webhook_id=$1 order by d.created_at desc limit $2 offset $3
+	`
+	var res entities.WebhookDeliveryList
+	err := withRetry(ctx, func() error {
+		res = nil
+		rows, err := conn(ctx, t.db).Query(ctx, query, webhookId, limit, offset)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			d, err := scanDelivery(rows)
+			if err != nil {
+				return err
+			}
+			res = append(res, d)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return entities.WebhookDeliveryList{}, err
+	}
+	return res, nil
+}
+
+// ClaimDeliveries забирает готовые к отправке доставки и откладывает их
+// следующую попытку на lease, чтобы другие экземпляры сервиса не отправили
+// их одновременно. Если отправитель упадет, доставка вернется в очередь
+// по истечении lease.
+func (t *WebhookRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (entities.WebhookDeliveryList, error) {
+	query := `
+		with d as (
+			update webhook_delivery set next_attempt_at=now() + make_interval(secs => $2::float8)
+			where id in (
+				select id from webhook_delivery
+				where status='pending' and next_attempt_at <= now()
+				order by next_attempt_at limit $1
+				for update skip locked
+			)
+			returning *
+		)
+		select ` + deliveryColumns + `, w.url, w.secret
+		from d join webhook w on w.id=d.webhook_id
+	`
+	rows, err := conn(ctx, t.db).Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res entities.WebhookDeliveryList
+	for rows.Next() {
+		var url, secret string
+		d, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		d.URL, d.Secret = url, secret
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+func (t *WebhookRepo) CompleteDelivery(ctx context.Context, id string, code int) error {
+	query := `
+		update webhook_delivery set status='delivered', attempts=attempts+1, response_code=$2,
+		last_error=null, delivered_at=now()
+		where id=$1
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id, code)
+	return err
+}
+
+// FailDelivery записывает неудачную попытку и планирует следующую через
+// retryIn; retryIn <= 0 означает, что попытки исчерпаны.
+func (t *WebhookRepo) FailDelivery(ctx context.Context, id string, code int, reason string, retryIn time.Duration) error {
+	query := `
+		update webhook_delivery set attempts=attempts+1, response_code=nullif($2, 0), last_error=$3,
+		status=case when $4::float8 > 0 then 'pending' else 'failed' end,
+		next_attempt_at=case when $4::float8 > 0 then now() + make_interval(secs => $4::float8) else next_attempt_at end
+		where id=$1
+	`
+	_, err := conn(ctx, t.db).Exec(ctx, query, id, code, reason, retryIn.Seconds())
+	return err
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+	"zadanie-6105/internal/delivery/middleware"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var ErrSigningDisabled = errors.New("ключ подписи журнала не настроен")
+
+type Audit interface {
+	GetEntries(ctx context.Context, entity entities.AuditEntity, id string, username string) (entities.AuditList, error)
+	VerifyChain(ctx context.Context, tenderId string, username string) (entities.ChainReport, error)
+	ChainHead(ctx context.Context, tenderId string, username string) (entities.ChainHead, error)
+}
+
+type AuditService struct {
+	repo   repositories.Audit
+	user   repositories.User
+	tender repositories.Tender
+	bid    repositories.Bid
+	authz  Authorizer
+	key    ed25519.PrivateKey
+}
+
+// key может быть nil, тогда экспорт подписанной головы цепочки недоступен.
+func NewAuditService(repo repositories.Audit, user repositories.User, tender repositories.Tender, bid repositories.Bid,
+	authz Authorizer, key ed25519.PrivateKey) Audit {
+	return &AuditService{
+		repo:   repo,
+		user:   user,
+		tender: tender,
+		bid:    bid,
+		authz:  authz,
+		key:    key,
+	}
+}
+
+// GetEntries отдает историю изменений сущности ответственным организации
+// тендера, чьей роли хватает на PermTenderView; историю предложения — если
+// они к тому же видят само предложение.
+func (s *AuditService) GetEntries(ctx context.Context, entity entities.AuditEntity, id string, username string) (entities.AuditList, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.GetEntries")
+	defer span.End()
+	tenderId := id
+	if entity == entities.AuditEntityBid {
+		userId, err := s.user.GetUserIDByUsername(ctx, username)
+		if err != nil {
+			return entities.AuditList{}, err
+		}
+		bid, err := s.bid.GetByID(ctx, id)
+		if err != nil {
+			return entities.AuditList{}, err
+		}
+		if err := s.authz.ViewBid(ctx, userId, bid); err != nil {
+			return entities.AuditList{}, err
+		}
+		tenderId = bid.TenderID
+	}
+	if err := s.checkAccess(ctx, tenderId, username); err != nil {
+		return entities.AuditList{}, err
+	}
+	return s.repo.GetByEntity(ctx, entity, id)
+}
+
+func (s *AuditService) VerifyChain(ctx context.Context, tenderId string, username string) (entities.ChainReport, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.VerifyChain")
+	defer span.End()
+	if err := s.checkAccess(ctx, tenderId, username); err != nil {
+		return entities.ChainReport{}, err
+	}
+	chain, err := s.repo.GetChain(ctx, tenderId)
+	if err != nil {
+		return entities.ChainReport{}, err
+	}
+	return VerifyChain(tenderId, chain), nil
+}
+
+// ChainHead подписывает последнюю запись цепочки, предварительно проверив
+// цепочку целиком: подписывать голову поврежденной истории нельзя.
+func (s *AuditService) ChainHead(ctx context.Context, tenderId string, username string) (entities.ChainHead, error) {
+	ctx, span := tracer.Start(ctx, "AuditService.ChainHead")
+	defer span.End()
+	if s.key == nil {
+		return entities.ChainHead{}, ErrSigningDisabled
+	}
+	report, err := s.VerifyChain(ctx, tenderId, username)
+	if err != nil {
+		return entities.ChainHead{}, err
+	}
+	if !report.Valid {
+		return entities.ChainHead{}, fmt.Errorf("цепочка тендера %s повреждена на записи %d: %s",
+			tenderId, report.BrokenAt.Seq, report.BrokenAt.Reason)
+	}
+	return SignChainHead(s.key, report, time.Now().UTC()), nil
+}
+
+func (s *AuditService) checkAccess(ctx context.Context, tenderId string, username string) error {
+	_, err := s.authz.AuthorizeTender(ctx, username, tenderId, entities.PermTenderView)
+	return err
+}
+
+// NewAuditEntry собирает запись журнала с изменившимися полями
+// до и после операции; before == nil для создания. tenderId задает
+// цепочку, в которую попадет запись.
+func NewAuditEntry(ctx context.Context, entity entities.AuditEntity, id string, tenderId string, action entities.AuditAction,
+	actorId string, organizationId string, before any, after any) (entities.AuditEntry, error) {
+	d, err := diff(before, after)
+	if err != nil {
+		return entities.AuditEntry{}, err
+	}
+	return entities.AuditEntry{
+		TenderID:       tenderId,
+		ActorID:        actorId,
+		OrganizationID: organizationId,
+		EntityType:     entity,
+		EntityID:       id,
+		Action:         action,
+		Diff:           d,
+		RequestID:      middleware.RequestIDFromContext(ctx),
+	}, nil
+}
+
+func diff(before any, after any) (json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+	res := struct {
+		Before map[string]any `json:"before,omitempty"`
+		After  map[string]any `json:"after"`
+	}{After: map[string]any{}}
+	for k, v := range a {
+		old, ok := b[k]
+		if ok && reflect.DeepEqual(old, v) {
+			continue
+		}
+		res.After[k] = v
+		if ok {
+			if res.Before == nil {
+				res.Before = map[string]any{}
+			}
+			res.Before[k] = old
+		}
+	}
+	return json.Marshal(res)
+}
+
+func toMap(v any) (map[string]any, error) {
+	res := map[string]any{}
+	if v == nil {
+		return res, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
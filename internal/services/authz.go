@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var ErrPermissionDenied = errors.New("роль пользователя в организации не дает права на это действие")
+
+// Authorizer проверяет права сотрудника в организации по матрице ролей
+// entities.OrganizationRole. Сервисы не смотрят на роли сами.
+type Authorizer interface {
+	// Authorize проверяет право perm у username в организации organizationId.
+	Authorize(ctx context.Context, username string, organizationId string, perm entities.Permission) error
+	// AuthorizeTender — то же для организации тендера; возвращает ее id.
+	AuthorizeTender(ctx context.Context, username string, tenderId string, perm entities.Permission) (string, error)
+	// AuthorizeBidder проверяет, что сотрудник userId может подавать
+	// предложения от имени организации хотя бы в одной из своих организаций.
+	AuthorizeBidder(ctx context.Context, userId string) error
+	// AuthorizeBidAuthor проверяет, что сотрудник userId действует за автора
+	// предложения: это сам автор или, если предложение от организации,
+	// сотрудник общей с автором организации с правом подавать предложения.
+	AuthorizeBidAuthor(ctx context.Context, userId string, bid entities.Bid) error
+	// Viewer определяет, от чьего имени читаются списки; "" — аноним.
+	Viewer(ctx context.Context, username string) (entities.Viewer, error)
+	// ViewTender и ViewBid возвращают ErrNoAccess, если сотрудник userId
+	// не должен видеть тендер или предложение.
+	ViewTender(ctx context.Context, userId string, tender entities.Tender) error
+	ViewBid(ctx context.Context, userId string, bid entities.Bid) error
+}
+
+type AuthorizerService struct {
+	user   repositories.User
+	tender repositories.Tender
+}
+
+func NewAuthorizer(user repositories.User, tender repositories.Tender) Authorizer {
+	return &AuthorizerService{
+		user:   user,
+		tender: tender,
+	}
+}
+
+// Authorize возвращает ErrNotResponsible, если сотрудник не связан с
+// организацией, и ErrPermissionDenied, если его роли не хватает. Управлять
+// любой организацией могут администраторы платформы.
+func (s *AuthorizerService) Authorize(ctx context.Context, username string, organizationId string, perm entities.Permission) error {
+	ctx, span := tracer.Start(ctx, "Authorizer.Authorize")
+	defer span.End()
+	role, err := s.user.GetRole(ctx, username, organizationId)
+	if err != nil {
+		return err
+	}
+	if role.Can(perm) {
+		return nil
+	}
+	if perm == entities.PermOrganizationManage {
+		admin, err := s.user.IsAdmin(ctx, username)
+		if err != nil {
+			return err
+		}
+		if admin {
+			return nil
+		}
+	}
+	if role == "" {
+		return ErrNotResponsible
+	}
+	return ErrPermissionDenied
+}
+
+func (s *AuthorizerService) AuthorizeTender(ctx context.Context, username string, tenderId string, perm entities.Permission) (string, error) {
+	ctx, span := tracer.Start(ctx, "Authorizer.AuthorizeTender")
+	defer span.End()
+	organizationId, err := s.tender.CheckTenderOrganization(ctx, tenderId)
+	if err != nil {
+		return "", err
+	}
+	err = s.Authorize(ctx, username, organizationId, perm)
+	if errors.Is(err, ErrNotResponsible) {
+		return "", ErrNoAccess
+	}
+	if err != nil {
+		return "", err
+	}
+	return organizationId, nil
+}
+
+func (s *AuthorizerService) AuthorizeBidder(ctx context.Context, userId string) error {
+	ctx, span := tracer.Start(ctx, "Authorizer.AuthorizeBidder")
+	defer span.End()
+	roles, err := s.user.GetRoles(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return ErrNotResponsible
+	}
+	for _, role := range roles {
+		if role.Can(entities.PermBidCreate) {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
+func (s *AuthorizerService) AuthorizeBidAuthor(ctx context.Context, userId string, bid entities.Bid) error {
+	ctx, span := tracer.Start(ctx, "Authorizer.AuthorizeBidAuthor")
+	defer span.End()
+	if bid.AuthorID == userId {
+		return nil
+	}
+	if bid.AuthorType != "organization" {
+		return ErrNoAccess
+	}
+	roles, err := s.user.GetRoles(ctx, userId)
+	if err != nil {
+		return err
+	}
+	authorRoles, err := s.user.GetRoles(ctx, bid.AuthorID)
+	if err != nil {
+		return err
+	}
+	for id := range authorRoles {
+		if roles[id].Can(entities.PermBidCreate) {
+			return nil
+		}
+	}
+	return ErrNoAccess
+}
+
+func (s *AuthorizerService) Viewer(ctx context.Context, username string) (entities.Viewer, error) {
+	if username == "" {
+		return entities.Viewer{}, nil
+	}
+	id, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Viewer{}, err
+	}
+	return entities.Viewer{ID: id}, nil
+}
+
+// ViewTender: опубликованный или закрытый тендер виден всем,
+// неопубликованный — сотрудникам его организации с PermTenderView.
+// Те же правила для списков задает tenderVisibleSQL в repositories.
+func (s *AuthorizerService) ViewTender(ctx context.Context, userId string, tender entities.Tender) error {
+	ctx, span := tracer.Start(ctx, "Authorizer.ViewTender")
+	defer span.End()
+	if tender.Status != entities.TenderStatusCreated {
+		return nil
+	}
+	roles, err := s.user.GetRoles(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if roles[tender.OrganizationID].Can(entities.PermTenderView) {
+		return nil
+	}
+	return ErrNoAccess
+}
+
+// ViewBid: предложение видят автор, сотрудники организаций автора, если
+// оно подано от организации, и ответственные организации тендера с
+// PermTenderView, когда оно подано. Для списков — bidVisibleSQL.
+func (s *AuthorizerService) ViewBid(ctx context.Context, userId string, bid entities.Bid) error {
+	ctx, span := tracer.Start(ctx, "Authorizer.ViewBid")
+	defer span.End()
+	if bid.AuthorID == userId {
+		return nil
+	}
+	roles, err := s.user.GetRoles(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if bid.AuthorType == "organization" {
+		authorRoles, err := s.user.GetRoles(ctx, bid.AuthorID)
+		if err != nil {
+			return err
+		}
+		for id := range authorRoles {
+			if _, ok := roles[id]; ok {
+				return nil
+			}
+		}
+	}
+	if bid.Status.Submitted() {
+		organizationId, err := s.tender.CheckTenderOrganization(ctx, bid.TenderID)
+		if err != nil {
+			return err
+		}
+		if roles[organizationId].Can(entities.PermTenderView) {
+			return nil
+		}
+	}
+	return ErrNoAccess
+}
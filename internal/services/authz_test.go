@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// userRoles отдает роли сотрудников: имя или id -> организация -> роль.
+type userRoles struct {
+	repositories.User
+	roles  map[string]map[string]entities.OrganizationRole
+	admins map[string]bool
+}
+
+func (u userRoles) GetRole(ctx context.Context, name string, organizationId string) (entities.OrganizationRole, error) {
+	return u.roles[name][organizationId], nil
+}
+
+func (u userRoles) GetRoles(ctx context.Context, userId string) (map[string]entities.OrganizationRole, error) {
+	return u.roles[userId], nil
+}
+
+func (u userRoles) IsAdmin(ctx context.Context, name string) (bool, error) {
+	return u.admins[name], nil
+}
+
+// tenderOrgs отдает организацию тендера по его id.
+type tenderOrgs struct {
+	repositories.Tender
+	orgs map[string]string
+}
+
+func (t tenderOrgs) CheckTenderOrganization(ctx context.Context, id string) (string, error) {
+	return t.orgs[id], nil
+}
+
+func TestAuthorize(t *testing.T) {
+	authz := NewAuthorizer(userRoles{
+		roles: map[string]map[string]entities.OrganizationRole{
+			"owner":     {"o1": entities.RoleOwner},
+			"evaluator": {"o1": entities.RoleEvaluator},
+			"bidder":    {"o1": entities.RoleBidder},
+		},
+		admins: map[string]bool{"admin": true},
+	}, nil)
+	for _, tc := range []struct {
+		user string
+		perm entities.Permission
+		err  error
+	}{
+		{"owner", entities.PermOrganizationManage, nil},
+		{"evaluator", entities.PermBidDecide, nil},
+		{"evaluator", entities.PermTenderEdit, ErrPermissionDenied},
+		{"bidder", entities.PermTenderView, ErrPermissionDenied},
+		{"admin", entities.PermOrganizationManage, nil},
+		{"admin", entities.PermTenderEdit, ErrNotResponsible},
+		{"stranger", entities.PermTenderView, ErrNotResponsible},
+	} {
+		err := authz.Authorize(context.Background(), tc.user, "o1", tc.perm)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s %s: got %v, want %v", tc.user, tc.perm, err, tc.err)
+		}
+	}
+}
+
+func TestAuthorizeTender(t *testing.T) {
+	authz := NewAuthorizer(userRoles{roles: map[string]map[string]entities.OrganizationRole{
+		"manager": {"o1": entities.RoleProcurementManager},
+		"viewer":  {"o1": entities.RoleViewer},
+	}}, tenderOrgs{orgs: map[string]string{"t1": "o1"}})
+	organizationId, err := authz.AuthorizeTender(context.Background(), "manager", "t1", entities.PermTenderEdit)
+	if err != nil || organizationId != "o1" {
+		t.Fatalf("manager: got %q, %v", organizationId, err)
+	}
+	if _, err := authz.AuthorizeTender(context.Background(), "viewer", "t1", entities.PermTenderEdit); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("viewer: got %v, want %v", err, ErrPermissionDenied)
+	}
+	if _, err := authz.AuthorizeTender(context.Background(), "stranger", "t1", entities.PermTenderView); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("stranger: got %v, want %v", err, ErrNoAccess)
+	}
+}
+
+func TestAuthorizeBidAuthor(t *testing.T) {
+	authz := NewAuthorizer(userRoles{roles: map[string]map[string]entities.OrganizationRole{
+		"author":    {"o1": entities.RoleBidder},
+		"colleague": {"o1": entities.RoleBidder},
+		"viewer":    {"o1": entities.RoleViewer},
+		"stranger":  {"o2": entities.RoleOwner},
+	}}, nil)
+	orgBid := entities.Bid{AuthorID: "author", AuthorType: "organization"}
+	userBid := entities.Bid{AuthorID: "author", AuthorType: "user"}
+	for _, tc := range []struct {
+		user string
+		bid  entities.Bid
+		err  error
+	}{
+		{"author", userBid, nil},
+		{"author", orgBid, nil},
+		{"colleague", orgBid, nil},
+		{"colleague", userBid, ErrNoAccess},
+		{"viewer", orgBid, ErrNoAccess},
+		{"stranger", orgBid, ErrNoAccess},
+	} {
+		err := authz.AuthorizeBidAuthor(context.Background(), tc.user, tc.bid)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s on %s bid: got %v, want %v", tc.user, tc.bid.AuthorType, err, tc.err)
+		}
+	}
+}
@@ -2,36 +2,62 @@ package services
 
 import (
 	"context"
+	"errors"
+	"time"
 	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/metrics"
 	"zadanie-6105/internal/repositories"
 	"zadanie-6105/internal/repositories/entities"
 )
 
+var (
+	ErrInvalidDecision = errors.New("решение должно быть approved или rejected")
+	ErrBidNotFound     = repositories.ErrBidNotFound
+	ErrTenderNotOpen   = errors.New("тендер не принимает предложения: он не опубликован или уже закрыт")
+)
+
 type Bid interface {
 	GetUserBids(ctx context.Context, params operation.BidParams, username string) (entities.BidList, error)
 	CreateBid(ctx context.Context, bid entities.Bid) (entities.Bid, error)
-	GetBidsForTender(ctx context.Context, tendor_id string, params operation.BidParams) (entities.BidList, error)
-	GetBidStatus(ctx context.Context, id string) (entities.BidStatus, error)
-	ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string) (entities.Bid, error)
+	GetBidsForTender(ctx context.Context, tendor_id string, params operation.BidParams, username string) (entities.BidList, error)
+	GetBidStatus(ctx context.Context, id string, username string) (entities.BidStatus, error)
+	ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string, username string) (entities.Bid, error)
 	SubmitBid(ctx context.Context, decision entities.BidStatus, bid_id string, username string) (entities.Bid, error)
-	EditBid(ctx context.Context, bid entities.Bid, id string) (entities.Bid, error)
+	EditBid(ctx context.Context, bid entities.Bid, id string, username string) (entities.Bid, error)
+	GetBidSignatures(ctx context.Context, id string, username string) (entities.BidSignatureList, error)
+	RevealBid(ctx context.Context, id string, price string, salt string, username string) (entities.Bid, error)
+	DisqualifyUnrevealed(ctx context.Context) (int, error)
+	ForceBidStatus(ctx context.Context, status entities.BidStatus, id string, actorId string, reason string) (entities.Bid, error)
 }
 
 type BidService struct {
 	repo   repositories.Bid
 	user   repositories.User
 	tender repositories.Tender
+	audit  repositories.Audit
+	keys   repositories.Key
+	tx     repositories.TxManager
+	events Events
+	authz  Authorizer
 }
 
-func NewBidService(repo repositories.Bid, user repositories.User, tender repositories.Tender) Bid {
+func NewBidService(repo repositories.Bid, user repositories.User, tender repositories.Tender, audit repositories.Audit,
+	keys repositories.Key, tx repositories.TxManager, events Events, authz Authorizer) Bid {
 	return &BidService{
 		repo:   repo,
 		user:   user,
 		tender: tender,
+		audit:  audit,
+		keys:   keys,
+		tx:     tx,
+		events: events,
+		authz:  authz,
 	}
 }
 
 func (s *BidService) GetUserBids(ctx context.Context, params operation.BidParams, username string) (entities.BidList, error) {
+	ctx, span := tracer.Start(ctx, "BidService.GetUserBids")
+	defer span.End()
 	id, err := s.user.GetUserIDByUsername(ctx, username)
 	if err != nil {
 		return entities.BidList{}, err
@@ -39,53 +65,416 @@ func (s *BidService) GetUserBids(ctx context.Context, params operation.BidParams
 	return s.repo.GetUserBids(ctx, params, id)
 }
 
+// CreateBid создает предложение. От имени организации (author_type
+// organization) предлагать может только автор с ролью, дающей
+// PermBidCreate. Логина в запросе нет, поэтому к базе обращаемся от имени
+// автора.
 func (s *BidService) CreateBid(ctx context.Context, bid entities.Bid) (entities.Bid, error) {
-	return s.repo.Create(ctx, bid)
+	ctx, span := tracer.Start(ctx, "BidService.CreateBid")
+	defer span.End()
+	ctx = repositories.WithViewer(ctx, entities.Viewer{ID: bid.AuthorID})
+	if bid.AuthorType == "organization" {
+		if err := s.authz.AuthorizeBidder(ctx, bid.AuthorID); err != nil {
+			return entities.Bid{}, err
+		}
+	}
+	tender, err := s.tender.GetByID(ctx, bid.TenderID)
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	// скрытый от автора тендер неотличим от несуществующего
+	if err := s.authz.ViewTender(ctx, bid.AuthorID, tender); errors.Is(err, ErrNoAccess) {
+		return entities.Bid{}, ErrTenderNotFound
+	} else if err != nil {
+		return entities.Bid{}, err
+	}
+	if tender.Status != entities.TenderStatusPublished {
+		return entities.Bid{}, ErrTenderNotOpen
+	}
+	if err := checkSealedBid(tender, &bid, true, time.Now().UTC()); err != nil {
+		return entities.Bid{}, err
+	}
+	var sig entities.BidSignature
+	if bid.Signature != nil {
+		signed := bid
+		signed.Version = 1
+		sig, err = verifyBidSignature(ctx, s.keys, bid.AuthorID, signed)
+		if err != nil {
+			return entities.Bid{}, err
+		}
+	}
+	var res entities.Bid
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		res, err = s.repo.Create(ctx, bid)
+		if err != nil {
+			return err
+		}
+		res.TenderID = bid.TenderID
+		if bid.Signature != nil {
+			if res.Signature, err = s.storeSignature(ctx, res.ID, sig); err != nil {
+				return err
+			}
+		}
+		err = s.record(ctx, res.ID, bid.TenderID, entities.AuditActionCreate, bid.AuthorID, nil, res)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, s.events, entities.EventBidCreated, tender.OrganizationID, bid.TenderID, res.ID, res)
+	})
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	metrics.BidsCreated.Inc()
+	return res, nil
 }
 
-func (s *BidService) GetBidsForTender(ctx context.Context, tendor_id string, params operation.BidParams) (entities.BidList, error) {
-	return s.repo.GetByTender(ctx, tendor_id, params)
+// GetBidsForTender отдает только видимые сотруднику предложения, см.
+// Authorizer.ViewBid.
+func (s *BidService) GetBidsForTender(ctx context.Context, tendor_id string, params operation.BidParams, username string) (entities.BidList, error) {
+	ctx, span := tracer.Start(ctx, "BidService.GetBidsForTender")
+	defer span.End()
+	viewer, err := s.authz.Viewer(ctx, username)
+	if err != nil {
+		return entities.BidList{}, err
+	}
+	tender, err := s.tender.GetByID(ctx, tendor_id)
+	if err != nil {
+		return entities.BidList{}, err
+	}
+	if err := s.authz.ViewTender(ctx, viewer.ID, tender); err != nil {
+		return entities.BidList{}, err
+	}
+	return s.repo.GetByTender(ctx, tendor_id, params, viewer)
 }
 
-func (s *BidService) GetBidStatus(ctx context.Context, id string) (entities.BidStatus, error) {
-	return s.repo.GetBidStatus(ctx, id)
+func (s *BidService) GetBidStatus(ctx context.Context, id string, username string) (entities.BidStatus, error) {
+	ctx, span := tracer.Start(ctx, "BidService.GetBidStatus")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	bid, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := s.authz.ViewBid(ctx, userId, bid); err != nil {
+		return "", err
+	}
+	return bid.Status, nil
 }
 
-func (s *BidService) ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string) (entities.Bid, error) {
-	return s.repo.ChangeBidStatus(ctx, status, id)
+func (s *BidService) ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string, username string) (entities.Bid, error) {
+	ctx, span := tracer.Start(ctx, "BidService.ChangeBidStatus")
+	defer span.End()
+	// решение по предложению принимается через SubmitBid, дисквалификация —
+	// сервисом
+	switch status {
+	case entities.BidStatusCreated, entities.BidStatusPublished, entities.BidStatusCanceled:
+	default:
+		return entities.Bid{}, ErrInvalidStatus
+	}
+	actorId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	var res entities.Bid
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authz.AuthorizeBidAuthor(ctx, actorId, before); err != nil {
+			return err
+		}
+		if decided(before.Status) {
+			return ErrBidDecided
+		}
+		res, err = s.repo.ChangeBidStatus(ctx, status, id)
+		if err != nil {
+			return err
+		}
+		res.TenderID = before.TenderID
+		err = s.record(ctx, id, before.TenderID, entities.AuditActionStatusChange, actorId, before, res)
+		if err != nil {
+			return err
+		}
+		if before.Status == status {
+			return nil
+		}
+		return s.publish(ctx, entities.EventBidStatusChanged, res)
+	})
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	return res, nil
+}
+
+// ForceBidStatus меняет статус в обход проверки прав — для tenderctl.
+// Причина попадает в журнал, а событие в outbox; actorId может быть пустым.
+func (s *BidService) ForceBidStatus(ctx context.Context, status entities.BidStatus, id string, actorId string, reason string) (entities.Bid, error) {
+	ctx, span := tracer.Start(ctx, "BidService.ForceBidStatus")
+	defer span.End()
+	var res entities.Bid
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		res, err = s.repo.ChangeBidStatus(ctx, status, id)
+		if err != nil {
+			return err
+		}
+		res.TenderID = before.TenderID
+		err = s.recordReason(ctx, id, before.TenderID, entities.AuditActionForcedStatusChange, actorId, reason, before, res)
+		if err != nil {
+			return err
+		}
+		if before.Status == status {
+			return nil
+		}
+		event := entities.EventBidStatusChanged
+		switch status {
+		case entities.BidStatusApproved:
+			event = entities.EventBidApproved
+		case entities.BidStatusRejected:
+			event = entities.EventBidRejected
+		case entities.BidStatusDisqualified:
+			event = entities.EventBidDisqualified
+		}
+		return s.publish(ctx, event, res)
+	})
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	return res, nil
 }
 
 // username это логин автора тендера!
 func (s *BidService) SubmitBid(ctx context.Context, decision entities.BidStatus, bid_id string, username string) (entities.Bid, error) {
-	organizationId, err := s.user.IsResponsible(ctx, username) //0 - значит юзер не остветсвенен за организацию
+	ctx, span := tracer.Start(ctx, "BidService.SubmitBid")
+	defer span.End()
+	if decision != entities.BidStatusApproved && decision != entities.BidStatusRejected {
+		return entities.Bid{}, ErrInvalidDecision
+	}
+	id, err := s.repo.GetTenderIDForBid(ctx, bid_id)
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	organizationId, err := s.authz.AuthorizeTender(ctx, username, id, entities.PermBidDecide)
 	if err != nil {
 		return entities.Bid{}, err
 	}
-	if organizationId == "" {
-		return entities.Bid{}, ErrNotResponsible
+	actorId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Bid{}, err
 	}
-	id, err := s.repo.GetTenderIDForBid(ctx, bid_id)
+	var bid entities.Bid
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// решения по предложениям тендера идут по очереди, иначе два
+		// одобрения закрыли бы тендер дважды
+		if err := s.tender.Lock(ctx, id); err != nil {
+			return err
+		}
+		before, err := s.repo.GetByID(ctx, bid_id)
+		if err != nil {
+			return err
+		}
+		// неподанное предложение организация тендера не видит
+		if !before.Status.Submitted() {
+			return ErrBidNotFound
+		}
+		tenderBefore, err := s.tender.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// решение принимается один раз и только пока тендер открыт
+		if before.Status != entities.BidStatusPublished || tenderBefore.Status != entities.TenderStatusPublished {
+			return ErrBidDecided
+		}
+		if tenderBefore.Sealed && (time.Now().UTC().Before(*tenderBefore.RevealDeadline) || before.RevealedAt == nil) {
+			return ErrRevealPending
+		}
+		bid, err = s.repo.ChangeBidStatus(ctx, decision, bid_id)
+		if err != nil {
+			return err
+		}
+		err = s.record(ctx, bid_id, id, entities.AuditActionDecision, actorId, before, bid)
+		if err != nil {
+			return err
+		}
+		event := entities.EventBidApproved
+		if decision == entities.BidStatusRejected {
+			event = entities.EventBidRejected
+		}
+		bid.TenderID = id
+		err = publish(ctx, s.events, event, organizationId, id, bid_id, bid)
+		if err != nil {
+			return err
+		}
+		if decision == entities.BidStatusRejected {
+			return nil
+		}
+		tender, err := s.tender.ChangeTenderStatus(ctx, entities.TenderStatusClosed, id)
+		if err != nil {
+			return err
+		}
+		entry, err := NewAuditEntry(ctx, entities.AuditEntityTender, id, id, entities.AuditActionStatusChange,
+			actorId, organizationId, tenderBefore, tender)
+		if err != nil {
+			return err
+		}
+		if err := s.audit.Create(ctx, entry); err != nil {
+			return err
+		}
+		return publish(ctx, s.events, entities.EventTenderStatusChanged, organizationId, id, "", tender)
+	})
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	metrics.BidDecisions.WithLabelValues(string(decision)).Inc()
+	if decision == entities.BidStatusApproved {
+		metrics.Awards.Inc()
+	}
+	return bid, nil
+}
+
+func (s *BidService) EditBid(ctx context.Context, bid entities.Bid, id string, username string) (entities.Bid, error) {
+	ctx, span := tracer.Start(ctx, "BidService.EditBid")
+	defer span.End()
+	actorId, err := s.user.GetUserIDByUsername(ctx, username)
 	if err != nil {
 		return entities.Bid{}, err
 	}
-	tenderOrganizationId, err := s.tender.CheckTenderOrganization(ctx, id)
+	before, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return entities.Bid{}, err
 	}
-	if tenderOrganizationId != organizationId {
-		return entities.Bid{}, ErrNoAccess
+	if err := s.authz.AuthorizeBidAuthor(ctx, actorId, before); err != nil {
+		return entities.Bid{}, err
+	}
+	if decided(before.Status) {
+		return entities.Bid{}, ErrBidDecided
 	}
-	bid, err := s.repo.ChangeBidStatus(ctx, entities.BidStatusApproved, bid_id)
+	tender, err := s.tender.GetByID(ctx, before.TenderID)
 	if err != nil {
 		return entities.Bid{}, err
 	}
-	_, err = s.tender.ChangeTenderStatus(ctx, entities.TenderStatusClosed, id)
+	if tender.Status != entities.TenderStatusPublished {
+		return entities.Bid{}, ErrTenderNotOpen
+	}
+	if err := checkSealedBid(tender, &bid, false, time.Now().UTC()); err != nil {
+		return entities.Bid{}, err
+	}
+	var sig entities.BidSignature
+	if bid.Signature != nil {
+		// подписывается новая версия целиком, а не только измененные поля
+		signed := before
+		if bid.Name != "" {
+			signed.Name = bid.Name
+		}
+		if bid.Description != "" {
+			signed.Description = bid.Description
+		}
+		if bid.Commitment != "" {
+			signed.Commitment = bid.Commitment
+		}
+		signed.Version = before.Version + 1
+		signed.Signature = bid.Signature
+		// версию подписывает автор предложения, даже если правит коллега
+		sig, err = verifyBidSignature(ctx, s.keys, before.AuthorID, signed)
+		if err != nil {
+			return entities.Bid{}, err
+		}
+	}
+	var res entities.Bid
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		res, err = s.repo.EditBid(ctx, bid, id)
+		if err != nil {
+			return err
+		}
+		res.TenderID = before.TenderID
+		if bid.Signature != nil {
+			if res.Signature, err = s.storeSignature(ctx, id, sig); err != nil {
+				return err
+			}
+		}
+		err = s.record(ctx, id, before.TenderID, entities.AuditActionEdit, actorId, before, res)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, s.events, entities.EventBidEdited, tender.OrganizationID, before.TenderID, id, res)
+	})
 	if err != nil {
 		return entities.Bid{}, err
 	}
-	return bid, nil
+	return res, nil
 }
 
-func (s *BidService) EditBid(ctx context.Context, bid entities.Bid, id string) (entities.Bid, error) {
-	return s.repo.EditBid(ctx, bid, id)
+// GetBidSignatures отдает подписи всех версий предложения тем, кому оно
+// видно (см. Authorizer.ViewBid).
+func (s *BidService) GetBidSignatures(ctx context.Context, id string, username string) (entities.BidSignatureList, error) {
+	ctx, span := tracer.Start(ctx, "BidService.GetBidSignatures")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.BidSignatureList{}, err
+	}
+	bid, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return entities.BidSignatureList{}, err
+	}
+	if err := s.authz.ViewBid(ctx, userId, bid); err != nil {
+		return entities.BidSignatureList{}, err
+	}
+	return s.keys.GetBidSignatures(ctx, id)
+}
+
+func (s *BidService) storeSignature(ctx context.Context, id string, sig entities.BidSignature) (*entities.BidSignature, error) {
+	sig.BidID = id
+	res, err := s.keys.CreateSignature(ctx, sig)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// decided сообщает, что по предложению принято решение или оно
+// дисквалифицировано; такие статусы автор не меняет.
+func decided(status entities.BidStatus) bool {
+	switch status {
+	case entities.BidStatusApproved, entities.BidStatusRejected, entities.BidStatusDisqualified:
+		return true
+	}
+	return false
+}
+
+// publish публикует событие предложения от имени организации его тендера.
+func (s *BidService) publish(ctx context.Context, typ entities.EventType, bid entities.Bid) error {
+	organizationId, err := s.tender.CheckTenderOrganization(ctx, bid.TenderID)
+	if err != nil {
+		return err
+	}
+	return publish(ctx, s.events, typ, organizationId, bid.TenderID, bid.ID, bid)
+}
+
+// record пишет в журнал изменение предложения; организацией записи
+// считается организация тендера.
+func (s *BidService) record(ctx context.Context, id string, tenderId string, action entities.AuditAction,
+	actorId string, before any, after any) error {
+	return s.recordReason(ctx, id, tenderId, action, actorId, "", before, after)
+}
+
+func (s *BidService) recordReason(ctx context.Context, id string, tenderId string, action entities.AuditAction,
+	actorId string, reason string, before any, after any) error {
+	organizationId, err := s.tender.CheckTenderOrganization(ctx, tenderId)
+	if err != nil {
+		return err
+	}
+	entry, err := NewAuditEntry(ctx, entities.AuditEntityBid, id, tenderId, action, actorId, organizationId, before, after)
+	if err != nil {
+		return err
+	}
+	entry.Reason = reason
+	return s.audit.Create(ctx, entry)
 }
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// bidRepo хранит предложения в памяти.
+type bidRepo struct {
+	repositories.Bid
+	bids map[string]entities.Bid
+}
+
+func (r *bidRepo) GetTenderIDForBid(ctx context.Context, id string) (string, error) {
+	return r.bids[id].TenderID, nil
+}
+
+func (r *bidRepo) GetByID(ctx context.Context, id string) (entities.Bid, error) {
+	bid, ok := r.bids[id]
+	if !ok {
+		return entities.Bid{}, ErrBidNotFound
+	}
+	return bid, nil
+}
+
+func (r *bidRepo) Lock(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *bidRepo) Reveal(ctx context.Context, id string, price string, salt string) (entities.Bid, error) {
+	bid := r.bids[id]
+	now := time.Now().UTC()
+	bid.Price, bid.Salt, bid.RevealedAt = price, salt, &now
+	r.bids[id] = bid
+	return bid, nil
+}
+
+// GetUnrevealed отдает все нераскрытые предложения, как снимок, сделанный до
+// смены их статусов.
+func (r *bidRepo) GetUnrevealed(ctx context.Context) (entities.BidList, error) {
+	var res entities.BidList
+	for _, bid := range r.bids {
+		if bid.RevealedAt == nil {
+			res = append(res, bid)
+		}
+	}
+	return res, nil
+}
+
+func (r *bidRepo) EditBid(ctx context.Context, bid entities.Bid, id string) (entities.Bid, error) {
+	res := r.bids[id]
+	res.Name = bid.Name
+	res.Version++
+	r.bids[id] = res
+	return res, nil
+}
+
+func (r *bidRepo) ChangeBidStatus(ctx context.Context, status entities.BidStatus, id string) (entities.Bid, error) {
+	bid := r.bids[id]
+	bid.Status = status
+	r.bids[id] = bid
+	return bid, nil
+}
+
+// tenderRepo хранит тендеры в памяти и считает закрытия.
+type tenderRepo struct {
+	repositories.Tender
+	tenders map[string]entities.Tender
+	closed  int
+}
+
+func (r *tenderRepo) Lock(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *tenderRepo) GetByID(ctx context.Context, id string) (entities.Tender, error) {
+	return r.tenders[id], nil
+}
+
+func (r *tenderRepo) CheckTenderOrganization(ctx context.Context, id string) (string, error) {
+	return r.tenders[id].OrganizationID, nil
+}
+
+func (r *tenderRepo) ChangeTenderStatus(ctx context.Context, status entities.TenderStatus, id string) (entities.Tender, error) {
+	tender := r.tenders[id]
+	tender.Status = status
+	r.tenders[id] = tender
+	if status == entities.TenderStatusClosed {
+		r.closed++
+	}
+	return tender, nil
+}
+
+// auditRepo считает записи журнала.
+type auditRepo struct {
+	repositories.Audit
+	entries []entities.AuditEntry
+}
+
+func (r *auditRepo) Create(ctx context.Context, entry entities.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+type userIDs struct {
+	repositories.User
+}
+
+func (userIDs) GetUserIDByUsername(ctx context.Context, name string) (string, error) {
+	return name, nil
+}
+
+// deciders разрешает решения по тендерам и правку предложений любому
+// сотруднику.
+type deciders struct {
+	Authorizer
+	tender repositories.Tender
+}
+
+func (a deciders) AuthorizeTender(ctx context.Context, username string, tenderId string, perm entities.Permission) (string, error) {
+	return a.tender.CheckTenderOrganization(ctx, tenderId)
+}
+
+func (a deciders) AuthorizeBidAuthor(ctx context.Context, userId string, bid entities.Bid) error {
+	return nil
+}
+
+type noTx struct{}
+
+func (noTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newDecisionService() (Bid, *tenderRepo, *auditRepo) {
+	bids := &bidRepo{bids: map[string]entities.Bid{
+		"b1": {ID: "b1", TenderID: "t1", Status: entities.BidStatusPublished},
+		"b2": {ID: "b2", TenderID: "t1", Status: entities.BidStatusPublished},
+		"b3": {ID: "b3", TenderID: "t2", Status: entities.BidStatusPublished},
+	}}
+	tenders := &tenderRepo{tenders: map[string]entities.Tender{
+		"t1": {ID: "t1", OrganizationID: "o1", Status: entities.TenderStatusPublished},
+		"t2": {ID: "t2", OrganizationID: "o1", Status: entities.TenderStatusClosed},
+	}}
+	audit := &auditRepo{}
+	return NewBidService(bids, userIDs{}, tenders, audit, nil, noTx{}, nil, deciders{tender: tenders}), tenders, audit
+}
+
+func TestSubmitBidDecidesOnce(t *testing.T) {
+	ctx := context.Background()
+	s, tenders, audit := newDecisionService()
+	if _, err := s.SubmitBid(ctx, entities.BidStatusApproved, "b1", "manager"); err != nil {
+		t.Fatal(err)
+	}
+	entries := len(audit.entries)
+	for _, tc := range []struct {
+		bid      string
+		decision entities.BidStatus
+	}{
+		{"b1", entities.BidStatusApproved},
+		{"b1", entities.BidStatusRejected},
+		// тендер уже закрыт одобрением b1
+		{"b2", entities.BidStatusApproved},
+	} {
+		if _, err := s.SubmitBid(ctx, tc.decision, tc.bid, "manager"); !errors.Is(err, ErrBidDecided) {
+			t.Errorf("%s %s: got %v, want %v", tc.decision, tc.bid, err, ErrBidDecided)
+		}
+	}
+	if tenders.closed != 1 || len(audit.entries) != entries {
+		t.Fatalf("tender closed %d times, %d extra audit entries", tenders.closed, len(audit.entries)-entries)
+	}
+}
+
+func TestSubmitBidApproveAfterReject(t *testing.T) {
+	ctx := context.Background()
+	s, tenders, _ := newDecisionService()
+	if _, err := s.SubmitBid(ctx, entities.BidStatusRejected, "b1", "manager"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SubmitBid(ctx, entities.BidStatusApproved, "b1", "manager"); !errors.Is(err, ErrBidDecided) {
+		t.Fatalf("approve after reject: got %v, want %v", err, ErrBidDecided)
+	}
+	if tenders.closed != 0 {
+		t.Fatalf("tender closed %d times", tenders.closed)
+	}
+	// отклонение одного предложения не мешает решению по другому
+	bid, err := s.SubmitBid(ctx, entities.BidStatusApproved, "b2", "manager")
+	if err != nil || bid.Status != entities.BidStatusApproved || tenders.closed != 1 {
+		t.Fatalf("approve b2: got %v, %v, closed %d", bid.Status, err, tenders.closed)
+	}
+}
+
+func TestEditBidBeforeDecision(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newDecisionService()
+	edited, err := s.EditBid(ctx, entities.Bid{Name: "Новое"}, "b1", "author")
+	if err != nil || edited.Name != "Новое" {
+		t.Fatalf("edit published bid: got %q, %v", edited.Name, err)
+	}
+	if _, err := s.SubmitBid(ctx, entities.BidStatusRejected, "b1", "manager"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EditBid(ctx, entities.Bid{Name: "После решения"}, "b1", "author"); !errors.Is(err, ErrBidDecided) {
+		t.Errorf("edit decided bid: got %v, want %v", err, ErrBidDecided)
+	}
+	if _, err := s.EditBid(ctx, entities.Bid{Name: "После закрытия"}, "b3", "author"); !errors.Is(err, ErrTenderNotOpen) {
+		t.Errorf("edit bid on closed tender: got %v, want %v", err, ErrTenderNotOpen)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// chainTimeLayout совпадает с to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US')
+// в функции audit_log_canonical.
+const chainTimeLayout = "2006-01-02T15:04:05.000000"
+
+// ChainHash повторяет вычисление хеша записи в базе (audit_log_hash):
+// SHA-256 от полей записи, каждое в виде "длина:значение".
+func ChainHash(e entities.AuditEntry) string {
+	fields := []string{
+		strconv.FormatInt(e.Seq, 10),
+		e.TenderID,
+		string(e.EntityType),
+		e.EntityID,
+		string(e.Action),
+		e.ActorID,
+		e.OrganizationID,
+		string(e.Diff),
+		e.Reason,
+		e.RequestID,
+		e.CreatedAt.Format(chainTimeLayout),
+		e.PrevHash,
+	}
+	var b strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%d:%s", len(f), f)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain проходит цепочку, упорядоченную по seq, и сообщает о первом
+// звене, которое не сходится.
+func VerifyChain(tenderId string, chain entities.AuditList) entities.ChainReport {
+	report := entities.ChainReport{TenderID: tenderId, Entries: len(chain), Valid: true}
+	prev := ""
+	for i, e := range chain {
+		var reason string
+		switch {
+		case e.Seq != int64(i+1):
+			reason = fmt.Sprintf("ожидался номер %d", i+1)
+		case e.PrevHash != prev:
+			reason = "prev_hash не совпадает с хешем предыдущей записи"
+		case ChainHash(e) != e.Hash:
+			reason = "хеш не совпадает с содержимым записи"
+		}
+		if reason != "" {
+			report.Valid = false
+			report.BrokenAt = &entities.ChainBreak{ID: e.ID, Seq: e.Seq, Reason: reason}
+			return report
+		}
+		prev = e.Hash
+	}
+	report.Head = prev
+	return report
+}
+
+// ChainHeadMessage — строка, которую подписывает сервис. Проверить подпись:
+// ed25519.Verify(publicKey, []byte(head.Message), signature).
+func ChainHeadMessage(tenderId string, seq int64, hash string, signedAt time.Time) string {
+	return fmt.Sprintf("tender-audit-head|%s|%d|%s|%s", tenderId, seq, hash, signedAt.Format(time.RFC3339))
+}
+
+func SignChainHead(key ed25519.PrivateKey, report entities.ChainReport, now time.Time) entities.ChainHead {
+	head := entities.ChainHead{
+		TenderID:  report.TenderID,
+		Seq:       int64(report.Entries),
+		Hash:      report.Head,
+		SignedAt:  now.Truncate(time.Second),
+		Algorithm: "ed25519",
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+	}
+	head.Message = ChainHeadMessage(head.TenderID, head.Seq, head.Hash, head.SignedAt)
+	head.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(head.Message)))
+	return head
+}
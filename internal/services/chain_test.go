@@ -0,0 +1,115 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+func TestChainHash(t *testing.T) {
+	// значение посчитано по формуле audit_log_hash из миграции 0003:
+	// длина поля в байтах, двоеточие и само поле
+	e := entities.AuditEntry{
+		Seq:            1,
+		TenderID:       "t1",
+		EntityType:     entities.AuditEntityTender,
+		EntityID:       "t1",
+		Action:         entities.AuditActionCreate,
+		ActorID:        "u1",
+		OrganizationID: "o1",
+		Diff:           json.RawMessage(`{"after":{"name":"Тендер"}}`),
+		RequestID:      "req-1",
+		CreatedAt:      time.Date(2026, 10, 19, 12, 30, 0, 123456789, time.UTC),
+	}
+	const want = "afdc0ab56495cb0c9cf9b95694d9a87bdf6ff34c8c3ad994c0a1ae493e31bbc4"
+	if got := ChainHash(e); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+// testChain строит правильную цепочку тендера t1 из n записей.
+func testChain(n int) entities.AuditList {
+	var chain entities.AuditList
+	prev := ""
+	for i := 1; i <= n; i++ {
+		e := entities.AuditEntry{
+			ID:         int64(i * 10),
+			Seq:        int64(i),
+			TenderID:   "t1",
+			EntityType: entities.AuditEntityTender,
+			EntityID:   "t1",
+			Action:     entities.AuditActionEdit,
+			Diff:       json.RawMessage(`{"after":{"version":` + strconv.Itoa(i) + `}}`),
+			CreatedAt:  time.Date(2026, 10, 19, 12, i, 0, 0, time.UTC),
+			PrevHash:   prev,
+		}
+		e.Hash = ChainHash(e)
+		prev = e.Hash
+		chain = append(chain, e)
+	}
+	return chain
+}
+
+func TestVerifyChain(t *testing.T) {
+	chain := testChain(3)
+	report := VerifyChain("t1", chain)
+	if !report.Valid || report.Entries != 3 || report.Head != chain[2].Hash || report.BrokenAt != nil {
+		t.Fatalf("valid chain: %+v", report)
+	}
+	if report := VerifyChain("t1", nil); !report.Valid || report.Head != "" {
+		t.Fatalf("empty chain: %+v", report)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		change func(entities.AuditList) entities.AuditList
+		seq    int64
+	}{
+		{"edited diff", func(c entities.AuditList) entities.AuditList {
+			c[1].Diff = json.RawMessage(`{"after":{"version":9}}`)
+			return c
+		}, 2},
+		{"edited reason", func(c entities.AuditList) entities.AuditList {
+			c[2].Reason = "задним числом"
+			return c
+		}, 3},
+		{"rehashed entry", func(c entities.AuditList) entities.AuditList {
+			c[0].ActorID = "u2"
+			c[0].Hash = ChainHash(c[0])
+			return c
+		}, 2},
+		{"deleted entry", func(c entities.AuditList) entities.AuditList {
+			return append(c[:1], c[2:]...)
+		}, 3},
+	} {
+		report := VerifyChain("t1", tc.change(testChain(3)))
+		if report.Valid || report.BrokenAt == nil || report.BrokenAt.Seq != tc.seq {
+			t.Errorf("%s: got %+v, want break at seq %d", tc.name, report, tc.seq)
+		}
+	}
+}
+
+func TestSignChainHead(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := testChain(2)
+	now := time.Date(2026, 10, 19, 13, 0, 0, 500, time.UTC)
+	head := SignChainHead(key, VerifyChain("t1", chain), now)
+	if head.Seq != 2 || head.Hash != chain[1].Hash || !head.SignedAt.Equal(now.Truncate(time.Second)) {
+		t.Fatalf("unexpected head %+v", head)
+	}
+	if head.Message != ChainHeadMessage("t1", 2, chain[1].Hash, head.SignedAt) {
+		t.Fatalf("unexpected message %q", head.Message)
+	}
+	publicKey, _ := base64.StdEncoding.DecodeString(head.PublicKey)
+	signature, _ := base64.StdEncoding.DecodeString(head.Signature)
+	if !ed25519.Verify(publicKey, []byte(head.Message), signature) {
+		t.Fatal("signature does not verify")
+	}
+}
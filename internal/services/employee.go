@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	netmail "net/mail"
+	"regexp"
+	"unicode/utf8"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrUsernameTaken   = repositories.ErrUsernameTaken
+	ErrInvalidEmployee = errors.New("логин — до 50 латинских букв, цифр, точек, дефисов и подчеркиваний, имя и фамилия — до 50 символов, email корректный, locale ru или en")
+
+	usernameRe = regexp.MustCompile(`^[A-Za-z0-9._-]{1,50}$`)
+)
+
+type Employee interface {
+	CreateEmployee(ctx context.Context, employee entities.Employee) (entities.Employee, error)
+	EditEmployee(ctx context.Context, employee entities.Employee, id string, username string) (entities.Employee, error)
+}
+
+type EmployeeService struct {
+	user repositories.User
+}
+
+func NewEmployeeService(user repositories.User) Employee {
+	return &EmployeeService{user: user}
+}
+
+// CreateEmployee регистрирует сотрудника. Администраторы назначаются только
+// через tenderctl.
+func (s *EmployeeService) CreateEmployee(ctx context.Context, employee entities.Employee) (entities.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.CreateEmployee")
+	defer span.End()
+	if !usernameRe.MatchString(employee.Username) {
+		return entities.Employee{}, ErrInvalidEmployee
+	}
+	if err := validateEmployee(&employee); err != nil {
+		return entities.Employee{}, err
+	}
+	employee.IsAdmin = false
+	return s.user.Create(ctx, employee)
+}
+
+// EditEmployee меняет имя, фамилию, email и язык писем. Править можно себя,
+// администраторам — любого сотрудника.
+func (s *EmployeeService) EditEmployee(ctx context.Context, employee entities.Employee, id string, username string) (entities.Employee, error) {
+	ctx, span := tracer.Start(ctx, "EmployeeService.EditEmployee")
+	defer span.End()
+	if err := validateEmployee(&employee); err != nil {
+		return entities.Employee{}, err
+	}
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Employee{}, err
+	}
+	if userId != id {
+		admin, err := s.user.IsAdmin(ctx, username)
+		if err != nil {
+			return entities.Employee{}, err
+		}
+		if !admin {
+			return entities.Employee{}, ErrNotAdmin
+		}
+	}
+	res, err := s.user.Edit(ctx, employee, id)
+	if errors.Is(err, ErrUserNotFound) {
+		return entities.Employee{}, ErrEmployeeNotFound
+	}
+	return res, err
+}
+
+func validateEmployee(employee *entities.Employee) error {
+	if utf8.RuneCountInString(employee.FirstName) > 50 || utf8.RuneCountInString(employee.LastName) > 50 {
+		return ErrInvalidEmployee
+	}
+	if employee.Locale != "" && employee.Locale != "ru" && employee.Locale != "en" {
+		return ErrInvalidEmployee
+	}
+	if employee.Email != "" {
+		addr, err := netmail.ParseAddress(employee.Email)
+		if err != nil || addr.Name != "" {
+			return ErrInvalidEmployee
+		}
+		employee.Email = addr.Address
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrInvalidKey       = errors.New("открытый ключ должен быть ключом Ed25519 в base64")
+	ErrInvalidSignature = errors.New("подпись предложения не прошла проверку")
+	ErrKeyNotFound      = repositories.ErrKeyNotFound
+)
+
+type Key interface {
+	RegisterKey(ctx context.Context, publicKey string, username string) (entities.EmployeeKey, error)
+	GetKeys(ctx context.Context, username string) (entities.EmployeeKeyList, error)
+	RevokeKey(ctx context.Context, id string, username string) (entities.EmployeeKey, error)
+}
+
+type KeyService struct {
+	repo repositories.Key
+	user repositories.User
+}
+
+func NewKeyService(repo repositories.Key, user repositories.User) Key {
+	return &KeyService{
+		repo: repo,
+		user: user,
+	}
+}
+
+func (s *KeyService) RegisterKey(ctx context.Context, publicKey string, username string) (entities.EmployeeKey, error) {
+	ctx, span := tracer.Start(ctx, "KeyService.RegisterKey")
+	defer span.End()
+	if _, err := decodePublicKey(publicKey); err != nil {
+		return entities.EmployeeKey{}, err
+	}
+	id, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.EmployeeKey{}, err
+	}
+	return s.repo.Create(ctx, entities.EmployeeKey{EmployeeID: id, PublicKey: publicKey})
+}
+
+func (s *KeyService) GetKeys(ctx context.Context, username string) (entities.EmployeeKeyList, error) {
+	ctx, span := tracer.Start(ctx, "KeyService.GetKeys")
+	defer span.End()
+	id, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.EmployeeKeyList{}, err
+	}
+	return s.repo.GetByEmployee(ctx, id)
+}
+
+func (s *KeyService) RevokeKey(ctx context.Context, id string, username string) (entities.EmployeeKey, error) {
+	ctx, span := tracer.Start(ctx, "KeyService.RevokeKey")
+	defer span.End()
+	employeeId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.EmployeeKey{}, err
+	}
+	return s.repo.Revoke(ctx, id, employeeId)
+}
+
+func decodePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// verifyBidSignature проверяет подпись версии предложения bid ключом сотрудника
+// signerId. Возвращает подпись, готовую к сохранению.
+func verifyBidSignature(ctx context.Context, keys repositories.Key, signerId string, bid entities.Bid) (entities.BidSignature, error) {
+	sig := *bid.Signature
+	key, err := keys.GetByID(ctx, sig.KeyID)
+	if errors.Is(err, repositories.ErrKeyNotFound) {
+		return entities.BidSignature{}, ErrInvalidSignature
+	}
+	if err != nil {
+		return entities.BidSignature{}, err
+	}
+	if key.EmployeeID != signerId || key.RevokedAt != nil {
+		return entities.BidSignature{}, ErrInvalidSignature
+	}
+	publicKey, err := decodePublicKey(key.PublicKey)
+	if err != nil {
+		return entities.BidSignature{}, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return entities.BidSignature{}, ErrInvalidSignature
+	}
+	payload, err := bid.SigningPayload()
+	if err != nil {
+		return entities.BidSignature{}, err
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return entities.BidSignature{}, ErrInvalidSignature
+	}
+	return entities.BidSignature{
+		Version:   bid.Version,
+		KeyID:     key.ID,
+		PublicKey: key.PublicKey,
+		Payload:   string(payload),
+		Signature: sig.Signature,
+	}, nil
+}
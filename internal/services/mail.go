@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	netmail "net/mail"
+	"time"
+	"zadanie-6105/internal/mail"
+	"zadanie-6105/internal/metrics"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var ErrInvalidMailSettings = errors.New("нужен корректный email или пустая строка, locale ru или en, mode off, instant или digest")
+
+const (
+	mailLease       = time.Minute
+	mailBatch       = 20
+	mailMaxAttempts = 8
+	mailBackoff     = time.Minute
+	mailMaxBackoff  = time.Hour
+	digestPeriod    = 24 * time.Hour
+	digestMaxItems  = 100
+)
+
+// mailTemplates — события, о которых приходит письмо.
+var mailTemplates = map[entities.EventType]string{
+	entities.EventBidCreated:      mail.TemplateBidCreated,
+	entities.EventBidApproved:     mail.TemplateBidDecision,
+	entities.EventBidRejected:     mail.TemplateBidDecision,
+	entities.EventTenderDeadline:  mail.TemplateTenderDeadline,
+	entities.EventTenderPublished: mail.TemplateTenderPublished,
+}
+
+// Mailer ставит в очередь письма о событии пользователям, которым по нему
+// создано уведомление.
+type Mailer interface {
+	Notify(ctx context.Context, event entities.Event, userIds []string) error
+}
+
+type Mail interface {
+	Mailer
+	GetSettings(ctx context.Context, username string) (entities.MailSettings, error)
+	SetSettings(ctx context.Context, settings entities.MailSettings, username string) (entities.MailSettings, error)
+	SendPending(ctx context.Context) (int, error)
+	SendDigests(ctx context.Context) (int, error)
+}
+
+type MailService struct {
+	repo          repositories.Mail
+	notifications repositories.Notification
+	user          repositories.User
+	tender        repositories.Tender
+	tx            repositories.TxManager
+	renderer      *mail.Renderer
+	transport     mail.Transport
+	from          string
+}
+
+func NewMailService(repo repositories.Mail, notifications repositories.Notification, user repositories.User,
+	tender repositories.Tender, tx repositories.TxManager, renderer *mail.Renderer, transport mail.Transport, from string) Mail {
+	return &MailService{
+		repo:          repo,
+		notifications: notifications,
+		user:          user,
+		tender:        tender,
+		tx:            tx,
+		renderer:      renderer,
+		transport:     transport,
+		from:          from,
+	}
+}
+
+// Notify отрисовывает письмо на языке каждого получателя; получатели без
+// адреса и выбравшие сводку или отключившие письма пропускаются.
+func (s *MailService) Notify(ctx context.Context, event entities.Event, userIds []string) error {
+	ctx, span := tracer.Start(ctx, "MailService.Notify")
+	defer span.End()
+	name, ok := mailTemplates[event.Type]
+	if !ok {
+		return nil
+	}
+	recipients, err := s.repo.GetRecipients(ctx, userIds)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+	var payload struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(event.Data, &payload); err != nil {
+		return err
+	}
+	data := mail.Data{Status: payload.Status}
+	if event.BidID != "" {
+		data.Bid = payload.Name
+	}
+	tender, err := s.tender.GetByID(ctx, event.TenderID)
+	if err != nil {
+		return err
+	}
+	data.Tender = tender.Name
+	if tender.BidDeadline != nil {
+		data.Deadline = tender.BidDeadline.UTC()
+	}
+	for _, r := range recipients {
+		data.FirstName = r.FirstName
+		if err := s.enqueue(ctx, r, name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MailService) enqueue(ctx context.Context, r entities.MailRecipient, name string, data mail.Data) error {
+	rendered, err := s.renderer.Render(r.Locale, name, data)
+	if err != nil {
+		return err
+	}
+	return s.repo.Enqueue(ctx, entities.MailMessage{
+		UserID:   r.UserID,
+		Template: name,
+		To:       r.Email,
+		Subject:  rendered.Subject,
+		Text:     rendered.Text,
+		HTML:     rendered.HTML,
+	})
+}
+
+func (s *MailService) GetSettings(ctx context.Context, username string) (entities.MailSettings, error) {
+	ctx, span := tracer.Start(ctx, "MailService.GetSettings")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.MailSettings{}, err
+	}
+	return s.repo.GetSettings(ctx, userId)
+}
+
+// SetSettings заменяет настройки целиком; пустой email отключает письма.
+func (s *MailService) SetSettings(ctx context.Context, settings entities.MailSettings, username string) (entities.MailSettings, error) {
+	ctx, span := tracer.Start(ctx, "MailService.SetSettings")
+	defer span.End()
+	var mode entities.MailMode
+	mode.Scan(string(settings.Mode))
+	if mode == "" || (settings.Locale != "ru" && settings.Locale != "en") {
+		return entities.MailSettings{}, ErrInvalidMailSettings
+	}
+	if settings.Email != "" {
+		addr, err := netmail.ParseAddress(settings.Email)
+		if err != nil || addr.Name != "" {
+			return entities.MailSettings{}, ErrInvalidMailSettings
+		}
+		settings.Email = addr.Address
+	}
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.MailSettings{}, err
+	}
+	if err := s.repo.SetSettings(ctx, userId, settings); err != nil {
+		return entities.MailSettings{}, err
+	}
+	return s.repo.GetSettings(ctx, userId)
+}
+
+// SendPending отправляет письма из очереди. Неудачные повторяются с
+// экспоненциальной задержкой, после mailMaxAttempts попыток письмо
+// помечается failed. Вызывается фоновым воркером.
+func (s *MailService) SendPending(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "MailService.SendPending")
+	defer span.End()
+	messages, err := s.repo.Claim(ctx, mailBatch, mailLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range messages {
+		err := s.transport.Send(ctx, mail.Message{
+			From:    s.from,
+			To:      m.To,
+			Subject: m.Subject,
+			Text:    m.Text,
+			HTML:    m.HTML,
+		})
+		if err == nil {
+			metrics.MailMessages.WithLabelValues("sent").Inc()
+			if err := s.repo.Complete(ctx, m.ID); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		attempt := m.Attempts + 1
+		var retryIn time.Duration
+		if attempt < mailMaxAttempts {
+			retryIn = mailBackoff << (attempt - 1)
+			if retryIn > mailMaxBackoff {
+				retryIn = mailMaxBackoff
+			}
+			metrics.MailMessages.WithLabelValues("retry").Inc()
+		} else {
+			metrics.MailMessages.WithLabelValues("failed").Inc()
+		}
+		if err := s.repo.Fail(ctx, m.ID, err.Error(), retryIn); err != nil {
+			return 0, err
+		}
+	}
+	return len(messages), nil
+}
+
+// SendDigests ставит в очередь сводку уведомлений за сутки тем, кто выбрал
+// режим digest и чья предыдущая сводка была не раньше чем сутки назад.
+// Пустая сводка не отправляется, но период все равно сдвигается.
+func (s *MailService) SendDigests(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "MailService.SendDigests")
+	defer span.End()
+	recipients, err := s.repo.GetDigestRecipients(ctx, digestPeriod)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, r := range recipients {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			items, err := s.notifications.GetSince(ctx, r.UserID, r.Since, digestMaxItems)
+			if err != nil {
+				return err
+			}
+			if len(items) > 0 {
+				data := mail.Data{FirstName: r.FirstName}
+				for _, n := range items {
+					data.Items = append(data.Items, mail.DigestItem{Title: n.Title, CreatedAt: n.CreatedAt})
+				}
+				if err := s.enqueue(ctx, r, mail.TemplateDigest, data); err != nil {
+					return err
+				}
+				sent++
+			}
+			return s.repo.MarkDigest(ctx, r.UserID)
+		})
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
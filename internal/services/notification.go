@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrNotificationNotFound = repositories.ErrNotificationNotFound
+	ErrInvalidPreferences   = errors.New("уведомления настраиваются только для типов событий, по которым они приходят")
+)
+
+// recipient — кому уведомление о событии.
+type recipient int
+
+const (
+	recipientBidAuthor recipient = iota
+	recipientTenderBidders
+	recipientTenderResponsibles
+)
+
+type notificationRule struct {
+	to    recipient
+	title string
+}
+
+// notificationRules — события, о которых уведомляют, и текст уведомления;
+// %s — название тендера или предложения из события.
+var notificationRules = map[entities.EventType]notificationRule{
+	entities.EventBidApproved:         {recipientBidAuthor, "Ваше предложение «%s» одобрено"},
+	entities.EventBidRejected:         {recipientBidAuthor, "Ваше предложение «%s» отклонено"},
+	entities.EventBidDisqualified:     {recipientBidAuthor, "Ваше предложение «%s» дисквалифицировано"},
+	entities.EventTenderEdited:        {recipientTenderBidders, "Тендер «%s», по которому вы подали предложение, изменен"},
+	entities.EventTenderStatusChanged: {recipientTenderBidders, "Тендер «%s», по которому вы подали предложение, сменил статус"},
+	entities.EventBidCreated:          {recipientTenderResponsibles, "Новое предложение «%s» по тендеру организации"},
+	entities.EventBidEdited:           {recipientTenderResponsibles, "Предложение «%s» изменено"},
+	entities.EventBidStatusChanged:    {recipientTenderResponsibles, "Подано предложение «%s» по тендеру организации"},
+	entities.EventBidRevealed:         {recipientTenderResponsibles, "Предложение «%s» раскрыто"},
+	entities.EventTenderDeadline:      {recipientTenderBidders, "Прием предложений по тендеру «%s» скоро закончится"},
+}
+
+// watchRules — события тендера, о которых уведомляют следящих за ним;
+// %s — название тендера. О чужих предложениях следящие узнают только
+// подачу и выбор победителя, как в потоке изменений.
+var watchRules = map[entities.EventType]string{
+	entities.EventTenderEdited:        "Отслеживаемый тендер «%s» изменен",
+	entities.EventTenderPublished:     "Отслеживаемый тендер «%s» опубликован",
+	entities.EventTenderStatusChanged: "Отслеживаемый тендер «%s» сменил статус",
+	entities.EventTenderDeadline:      "Прием предложений по отслеживаемому тендеру «%s» скоро закончится",
+	entities.EventBidCreated:          "Новое предложение по отслеживаемому тендеру «%s»",
+	entities.EventBidApproved:         "По отслеживаемому тендеру «%s» выбран победитель",
+}
+
+const searchMatchTitle = "Опубликован тендер «%s» по поиску «%s»"
+
+type Notification interface {
+	Events
+	GetNotifications(ctx context.Context, username string, unread bool, limit int, offset int) (entities.NotificationList, error)
+	MarkRead(ctx context.Context, id string, username string) (entities.Notification, error)
+	MarkAllRead(ctx context.Context, username string) (int, error)
+	GetPreferences(ctx context.Context, username string) (entities.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, prefs entities.NotificationPreferences, username string) (entities.NotificationPreferences, error)
+}
+
+type NotificationService struct {
+	repo   repositories.Notification
+	user   repositories.User
+	tender repositories.Tender
+	search repositories.Search
+	tx     repositories.TxManager
+	mailer Mailer
+}
+
+// NewNotificationService — mailer может быть nil, тогда письма не отправляются.
+func NewNotificationService(repo repositories.Notification, user repositories.User, tender repositories.Tender,
+	search repositories.Search, tx repositories.TxManager, mailer Mailer) Notification {
+	return &NotificationService{
+		repo:   repo,
+		user:   user,
+		tender: tender,
+		search: search,
+		tx:     tx,
+		mailer: mailer,
+	}
+}
+
+// notificationGroup — одно уведомление для нескольких получателей; mail —
+// дублировать ли его письмом.
+type notificationGroup struct {
+	title   string
+	payload json.RawMessage
+	userIds []string
+	mail    bool
+}
+
+// Publish создает уведомления о событии: по notificationRules, следящим за
+// тендером и владельцам подошедших сохраненных поисков. Получатель получает
+// не больше одного уведомления о событии — первое из перечисленных. В той же
+// транзакции ставятся письма тем, кому уведомление создано впервые.
+// Вызывается диспетчером outbox, поэтому событие может прийти повторно.
+func (s *NotificationService) Publish(ctx context.Context, event entities.Event) error {
+	ctx, span := tracer.Start(ctx, "NotificationService.Publish")
+	defer span.End()
+	var data struct {
+		Name     string `json:"name"`
+		AuthorID string `json:"author_id"`
+		Status   string `json:"status"`
+	}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return err
+	}
+	var groups []notificationGroup
+	if rule, ok := notificationRules[event.Type]; ok {
+		var (
+			userIds []string
+			err     error
+		)
+		switch rule.to {
+		case recipientBidAuthor:
+			userIds = []string{data.AuthorID}
+		case recipientTenderBidders:
+			userIds, err = s.repo.GetTenderBidders(ctx, event.TenderID)
+		case recipientTenderResponsibles:
+			// Неподанные предложения организации тендера не видны.
+			if entities.BidStatus(data.Status).Submitted() {
+				userIds, err = s.repo.GetResponsibles(ctx, event.OrganizationID, entities.RolesWith(entities.PermTenderView))
+			}
+		}
+		if err != nil {
+			return err
+		}
+		groups = append(groups, notificationGroup{fmt.Sprintf(rule.title, data.Name), event.Data, userIds, true})
+	}
+	if title, ok := watchRules[event.Type]; ok {
+		group, err := s.watchers(ctx, event, title, data.Name, data.Status)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, group)
+	}
+	if event.Type == entities.EventTenderPublished {
+		matches, err := s.search.Match(ctx, event.TenderID)
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			groups = append(groups, notificationGroup{
+				title:   fmt.Sprintf(searchMatchTitle, data.Name, m.Name),
+				payload: event.Data,
+				userIds: []string{m.UserID},
+				mail:    m.Channel == entities.SearchChannelEmail,
+			})
+		}
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var mail []string
+		for _, g := range groups {
+			if len(g.userIds) == 0 {
+				continue
+			}
+			created, err := s.repo.Create(ctx, entities.Notification{
+				EventID:   event.ID,
+				EventType: event.Type,
+				TenderID:  event.TenderID,
+				BidID:     event.BidID,
+				Title:     g.title,
+				Payload:   g.payload,
+			}, g.userIds)
+			if err != nil {
+				return err
+			}
+			if g.mail {
+				mail = append(mail, created...)
+			}
+		}
+		if s.mailer == nil || len(mail) == 0 {
+			return nil
+		}
+		return s.mailer.Notify(ctx, event, mail)
+	})
+}
+
+// watchers — уведомление следящим за тендером. У событий предложений в нем
+// название тендера и только статус предложения.
+func (s *NotificationService) watchers(ctx context.Context, event entities.Event, title string,
+	name string, status string) (notificationGroup, error) {
+	userIds, err := s.search.GetWatchers(ctx, event.TenderID)
+	if err != nil || len(userIds) == 0 {
+		return notificationGroup{}, err
+	}
+	if event.BidID == "" {
+		return notificationGroup{fmt.Sprintf(title, name), event.Data, userIds, true}, nil
+	}
+	tender, err := s.tender.GetByID(ctx, event.TenderID)
+	if err != nil {
+		return notificationGroup{}, err
+	}
+	payload, err := json.Marshal(map[string]string{"status": status})
+	if err != nil {
+		return notificationGroup{}, err
+	}
+	return notificationGroup{fmt.Sprintf(title, tender.Name), payload, userIds, false}, nil
+}
+
+func (s *NotificationService) GetNotifications(ctx context.Context, username string, unread bool, limit int, offset int) (entities.NotificationList, error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.GetNotifications")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.NotificationList{}, err
+	}
+	return s.repo.GetByUser(ctx, userId, unread, limit, offset)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id string, username string) (entities.Notification, error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.MarkRead")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Notification{}, err
+	}
+	return s.repo.MarkRead(ctx, id, userId)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, username string) (int, error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.MarkAllRead")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.MarkAllRead(ctx, userId)
+}
+
+// GetPreferences возвращает настройки по всем типам уведомлений; не
+// настроенные пользователем включены.
+func (s *NotificationService) GetPreferences(ctx context.Context, username string) (entities.NotificationPreferences, error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.GetPreferences")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.preferences(ctx, userId)
+}
+
+// SetPreferences меняет только переданные типы, остальные остаются как были.
+func (s *NotificationService) SetPreferences(ctx context.Context, prefs entities.NotificationPreferences, username string) (entities.NotificationPreferences, error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.SetPreferences")
+	defer span.End()
+	for typ := range prefs {
+		if _, ok := notificationRules[typ]; !ok {
+			return nil, ErrInvalidPreferences
+		}
+	}
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPreferences(ctx, userId, prefs); err != nil {
+		return nil, err
+	}
+	return s.preferences(ctx, userId)
+}
+
+func (s *NotificationService) preferences(ctx context.Context, userId string) (entities.NotificationPreferences, error) {
+	stored, err := s.repo.GetPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	res := make(entities.NotificationPreferences, len(notificationRules))
+	for typ := range notificationRules {
+		res[typ] = true
+	}
+	for typ, enabled := range stored {
+		if _, ok := res[typ]; ok {
+			res[typ] = enabled
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrOrganizationNotFound = repositories.ErrOrganizationNotFound
+	ErrNotAdmin             = errors.New("действие доступно только администраторам платформы")
+	ErrInvalidOrganization  = errors.New("у организации должны быть название до 100 символов и тип IE, LLC или JSC")
+	ErrOrganizationInUse    = errors.New("у организации есть тендеры, удалить ее нельзя")
+	ErrResponsibleNotFound  = repositories.ErrResponsibleNotFound
+	ErrInvitationNotFound   = repositories.ErrInvitationNotFound
+	ErrEmployeeNotFound     = errors.New("сотрудник не найден")
+	ErrAlreadyResponsible   = errors.New("сотрудник уже ответственен за организацию")
+	ErrLastOwner            = errors.New("нельзя убрать или понизить последнего владельца организации")
+	ErrLastResponsible      = errors.New("нельзя убрать последнего ответственного организации")
+	ErrInvalidRole          = errors.New("роль — owner, procurement_manager, evaluator, viewer или bidder")
+	ErrInvalidRequisites    = errors.New("у IE нужны ИНН из 12 цифр и ОГРНИП без КПП, у LLC и JSC — ИНН из 10 цифр, ОГРН и КПП; контрольные суммы должны сходиться")
+	ErrRequisitesTaken      = repositories.ErrRequisitesTaken
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type Organization interface {
+	GetOrganizations(ctx context.Context, username string, limit int, offset int) (entities.OrganizationList, error)
+	GetOrganization(ctx context.Context, id string, username string) (entities.Organization, error)
+	CreateOrganization(ctx context.Context, org entities.Organization, username string) (entities.Organization, error)
+	EditOrganization(ctx context.Context, org entities.Organization, id string, username string) (entities.Organization, error)
+	DeleteOrganization(ctx context.Context, id string, username string) error
+	GetResponsibles(ctx context.Context, id string, username string) (entities.EmployeeList, error)
+	InviteResponsible(ctx context.Context, id string, invitee string, role entities.OrganizationRole,
+		username string) (entities.Invitation, error)
+	SetRole(ctx context.Context, id string, employeeId string, role entities.OrganizationRole, username string) error
+	AcceptInvitation(ctx context.Context, token string, username string) (entities.Organization, error)
+	RemoveResponsible(ctx context.Context, id string, employeeId string, username string) error
+}
+
+type OrganizationService struct {
+	repo  repositories.Organization
+	user  repositories.User
+	tx    repositories.TxManager
+	authz Authorizer
+}
+
+func NewOrganizationService(repo repositories.Organization, user repositories.User, tx repositories.TxManager,
+	authz Authorizer) Organization {
+	return &OrganizationService{
+		repo:  repo,
+		user:  user,
+		tx:    tx,
+		authz: authz,
+	}
+}
+
+func (s *OrganizationService) GetOrganizations(ctx context.Context, username string, limit int, offset int) (entities.OrganizationList, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.GetOrganizations")
+	defer span.End()
+	if _, err := s.user.GetUserIDByUsername(ctx, username); err != nil {
+		return nil, err
+	}
+	return s.repo.GetList(ctx, limit, offset)
+}
+
+func (s *OrganizationService) GetOrganization(ctx context.Context, id string, username string) (entities.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.GetOrganization")
+	defer span.End()
+	if _, err := s.user.GetUserIDByUsername(ctx, username); err != nil {
+		return entities.Organization{}, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// CreateOrganization доступно только администраторам: у новой организации
+// еще нет ответственных.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, org entities.Organization, username string) (entities.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.CreateOrganization")
+	defer span.End()
+	if err := validateOrganization(&org, true); err != nil {
+		return entities.Organization{}, err
+	}
+	if !org.ValidRequisites() {
+		return entities.Organization{}, ErrInvalidRequisites
+	}
+	admin, err := s.user.IsAdmin(ctx, username)
+	if err != nil {
+		return entities.Organization{}, err
+	}
+	if !admin {
+		return entities.Organization{}, ErrNotAdmin
+	}
+	return s.repo.Create(ctx, org)
+}
+
+// EditOrganization меняет только переданные поля. Если меняются тип или
+// реквизиты, проверяются реквизиты, которые получатся после изменения.
+func (s *OrganizationService) EditOrganization(ctx context.Context, org entities.Organization, id string, username string) (entities.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.EditOrganization")
+	defer span.End()
+	if err := validateOrganization(&org, false); err != nil {
+		return entities.Organization{}, err
+	}
+	if err := s.checkAccess(ctx, id, username); err != nil {
+		return entities.Organization{}, err
+	}
+	if org.Type != "" || org.INN != "" || org.OGRN != "" || org.KPP != "" {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return entities.Organization{}, err
+		}
+		if org.Type != "" {
+			current.Type = org.Type
+			if org.Type == entities.OrganizationTypeIE {
+				current.KPP = ""
+			}
+		}
+		if org.INN != "" {
+			current.INN = org.INN
+		}
+		if org.OGRN != "" {
+			current.OGRN = org.OGRN
+		}
+		if org.KPP != "" {
+			current.KPP = org.KPP
+		}
+		if !current.ValidRequisites() {
+			return entities.Organization{}, ErrInvalidRequisites
+		}
+	}
+	return s.repo.Edit(ctx, org, id)
+}
+
+// DeleteOrganization удаляет организацию без тендеров: тендеры удалились бы
+// вместе с ней.
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id string, username string) error {
+	ctx, span := tracer.Start(ctx, "OrganizationService.DeleteOrganization")
+	defer span.End()
+	if err := s.checkAccess(ctx, id, username); err != nil {
+		return err
+	}
+	inUse, err := s.repo.HasTenders(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrOrganizationInUse
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *OrganizationService) GetResponsibles(ctx context.Context, id string, username string) (entities.EmployeeList, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.GetResponsibles")
+	defer span.End()
+	if _, err := s.user.GetUserIDByUsername(ctx, username); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetResponsibles(ctx, id)
+}
+
+// InviteResponsible создает приглашение invitee в ответственные с ролью
+// role, по умолчанию viewer. Токен есть только в ответе: пригласивший
+// передает его сотруднику сам.
+func (s *OrganizationService) InviteResponsible(ctx context.Context, id string, invitee string, role entities.OrganizationRole,
+	username string) (entities.Invitation, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.InviteResponsible")
+	defer span.End()
+	if role == "" {
+		role = entities.RoleViewer
+	}
+	if err := validateRole(role); err != nil {
+		return entities.Invitation{}, err
+	}
+	if err := s.checkAccess(ctx, id, username); err != nil {
+		return entities.Invitation{}, err
+	}
+	inviteeId, err := s.user.GetUserIDByUsername(ctx, invitee)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return entities.Invitation{}, ErrEmployeeNotFound
+		}
+		return entities.Invitation{}, err
+	}
+	current, err := s.user.GetRole(ctx, invitee, id)
+	if err != nil {
+		return entities.Invitation{}, err
+	}
+	if current != "" {
+		return entities.Invitation{}, ErrAlreadyResponsible
+	}
+	inviterId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Invitation{}, err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return entities.Invitation{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	res, err := s.repo.CreateInvitation(ctx, id, inviteeId, inviterId, role, invitationHash(token), invitationTTL)
+	if err != nil {
+		return entities.Invitation{}, err
+	}
+	res.Token = token
+	return res, nil
+}
+
+// AcceptInvitation делает приглашенного ответственным за организацию
+// с ролью из приглашения.
+// Токен действует один раз и только для того, кого пригласили.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, token string, username string) (entities.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.AcceptInvitation")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Organization{}, err
+	}
+	if token == "" {
+		return entities.Organization{}, ErrInvitationNotFound
+	}
+	var res entities.Organization
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		organizationId, role, err := s.repo.AcceptInvitation(ctx, invitationHash(token), userId)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.AddResponsible(ctx, organizationId, userId, role); err != nil {
+			return err
+		}
+		res, err = s.repo.GetByID(ctx, organizationId)
+		return err
+	})
+	if err != nil {
+		return entities.Organization{}, err
+	}
+	return res, nil
+}
+
+// RemoveResponsible убирает сотрудника из ответственных, в том числе самого
+// себя. Последнего ответственного или владельца убрать нельзя: организацией
+// стало бы некому управлять.
+func (s *OrganizationService) RemoveResponsible(ctx context.Context, id string, employeeId string, username string) error {
+	ctx, span := tracer.Start(ctx, "OrganizationService.RemoveResponsible")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if userId != employeeId {
+		if err := s.checkAccess(ctx, id, username); err != nil {
+			return err
+		}
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkLastResponsible(ctx, id, employeeId, ""); err != nil {
+			return err
+		}
+		return s.repo.RemoveResponsible(ctx, id, employeeId)
+	})
+}
+
+// SetRole меняет роль ответственного. Доступно владельцам и администраторам.
+func (s *OrganizationService) SetRole(ctx context.Context, id string, employeeId string, role entities.OrganizationRole,
+	username string) error {
+	ctx, span := tracer.Start(ctx, "OrganizationService.SetRole")
+	defer span.End()
+	if err := validateRole(role); err != nil {
+		return err
+	}
+	if err := s.checkAccess(ctx, id, username); err != nil {
+		return err
+	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkLastResponsible(ctx, id, employeeId, role); err != nil {
+			return err
+		}
+		return s.repo.SetRole(ctx, id, employeeId, role)
+	})
+}
+
+// checkLastResponsible не дает оставить организацию без ответственных или
+// без владельцев, когда employeeId получает роль role ("" — убирается из
+// ответственных). Вызывается в транзакции: организация блокируется до ее
+// конца.
+func (s *OrganizationService) checkLastResponsible(ctx context.Context, id string, employeeId string, role entities.OrganizationRole) error {
+	if err := s.repo.Lock(ctx, id); err != nil {
+		return err
+	}
+	responsibles, err := s.repo.GetResponsibles(ctx, id)
+	if err != nil {
+		return err
+	}
+	var (
+		found  *entities.Employee
+		owners int
+	)
+	for i, e := range responsibles {
+		if e.ID == employeeId {
+			found = &responsibles[i]
+		}
+		if e.Role == entities.RoleOwner {
+			owners++
+		}
+	}
+	if found == nil {
+		return ErrResponsibleNotFound
+	}
+	if role == "" && len(responsibles) == 1 {
+		return ErrLastResponsible
+	}
+	if found.Role == entities.RoleOwner && role != entities.RoleOwner && owners == 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func validateRole(role entities.OrganizationRole) error {
+	var r entities.OrganizationRole
+	r.Scan(string(role))
+	if r == "" {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+func invitationHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkAccess пропускает администраторов и тех, кому роль позволяет
+// управлять организацией.
+func (s *OrganizationService) checkAccess(ctx context.Context, id string, username string) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.authz.Authorize(ctx, username, id, entities.PermOrganizationManage)
+}
+
+// validateOrganization проверяет поля; full — при создании, когда название
+// и тип обязательны.
+func validateOrganization(org *entities.Organization, full bool) error {
+	org.Name = strings.TrimSpace(org.Name)
+	org.INN = strings.TrimSpace(org.INN)
+	org.OGRN = strings.TrimSpace(org.OGRN)
+	org.KPP = strings.ToUpper(strings.TrimSpace(org.KPP))
+	if utf8.RuneCountInString(org.Name) > 100 || (full && org.Name == "") {
+		return ErrInvalidOrganization
+	}
+	if org.Type != "" || full {
+		var typ entities.OrganizationType
+		typ.Scan(string(org.Type))
+		if typ == "" {
+			return ErrInvalidOrganization
+		}
+	}
+	return nil
+}
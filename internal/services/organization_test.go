@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// responsibles хранит ответственных одной организации в памяти.
+type responsibles struct {
+	repositories.Organization
+	list entities.EmployeeList
+}
+
+func (r *responsibles) Lock(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *responsibles) GetResponsibles(ctx context.Context, id string) (entities.EmployeeList, error) {
+	return r.list, nil
+}
+
+func (r *responsibles) RemoveResponsible(ctx context.Context, organizationID string, userID string) error {
+	for i, e := range r.list {
+		if e.ID == userID {
+			r.list = append(r.list[:i], r.list[i+1:]...)
+			return nil
+		}
+	}
+	return ErrResponsibleNotFound
+}
+
+func TestRemoveResponsibleKeepsOrganizationManaged(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		list entities.EmployeeList
+		err  error
+	}{
+		{"only responsible", entities.EmployeeList{{ID: "u1", Role: entities.RoleOwner}}, ErrLastResponsible},
+		{"only responsible without owner role", entities.EmployeeList{{ID: "u1", Role: entities.RoleViewer}}, ErrLastResponsible},
+		{"last owner", entities.EmployeeList{{ID: "u1", Role: entities.RoleOwner}, {ID: "u2", Role: entities.RoleViewer}}, ErrLastOwner},
+		{"one of owners", entities.EmployeeList{{ID: "u1", Role: entities.RoleOwner}, {ID: "u2", Role: entities.RoleOwner}}, nil},
+		{"not an owner", entities.EmployeeList{{ID: "u1", Role: entities.RoleBidder}, {ID: "u2", Role: entities.RoleOwner}}, nil},
+	} {
+		repo := &responsibles{list: tc.list}
+		s := NewOrganizationService(repo, userIDs{}, noTx{}, nil)
+		// сотрудник снимает себя сам, права владельца не нужны
+		err := s.RemoveResponsible(context.Background(), "o1", "u1", "u1")
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+		if removed := len(repo.list) < len(tc.list); removed != (tc.err == nil) {
+			t.Errorf("%s: removed %v", tc.name, removed)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type OutboxPublisher struct {
+	repo repositories.Outbox
+}
+
+// NewOutboxPublisher возвращает Events, который пишет события в outbox.
+// Вызванный внутри TxManager.WithinTx, он сохраняет событие в той же
+// транзакции, что и изменение; дальше его разносит outbox.Dispatcher.
+func NewOutboxPublisher(repo repositories.Outbox) Events {
+	return &OutboxPublisher{repo: repo}
+}
+
+func (p *OutboxPublisher) Publish(ctx context.Context, event entities.Event) error {
+	ctx, span := tracer.Start(ctx, "OutboxPublisher.Publish")
+	defer span.End()
+	return p.repo.Add(ctx, event)
+}
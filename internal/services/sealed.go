@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+	"zadanie-6105/internal/metrics"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrInvalidDeadlines   = errors.New("у закрытого тендера должны быть bid_deadline в будущем и reveal_deadline позже него")
+	ErrInvalidCommitment  = errors.New("для закрытого тендера нужно обязательство: hex sha256 от \"price:salt\"")
+	ErrBiddingClosed      = errors.New("прием предложений по тендеру завершен")
+	ErrNotSealed          = errors.New("тендер не закрытый, раскрывать нечего")
+	ErrRevealWindow       = errors.New("раскрытие возможно только между bid_deadline и reveal_deadline")
+	ErrInvalidReveal      = errors.New("цена должна иметь вид 1500.00, соль — не короче 16 символов")
+	ErrAlreadyRevealed    = errors.New("предложение уже раскрыто или дисквалифицировано")
+	ErrNotRevealable      = errors.New("раскрыть можно только поданное предложение, по которому еще нет решения")
+	ErrCommitmentMismatch = errors.New("цена и соль не совпадают с обязательством, предложение дисквалифицировано")
+	ErrRevealPending      = errors.New("решение по закрытому тендеру принимается после окончания раскрытия и только по раскрытым предложениям")
+	ErrInvalidStatus      = errors.New("автор может перевести предложение только в created, published или canceled")
+	ErrBidDecided         = errors.New("по предложению уже принято решение, его статус не меняется")
+)
+
+var (
+	commitmentRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	priceRe      = regexp.MustCompile(`^\d{1,18}\.\d{2}$`)
+)
+
+const minSaltLength = 16
+
+// validateSealed проверяет сроки закрытого тендера и приводит их к UTC:
+// в базе они хранятся без часового пояса.
+func validateSealed(tender *entities.Tender, now time.Time) error {
+	if !tender.Sealed {
+		tender.BidDeadline, tender.RevealDeadline = nil, nil
+		return nil
+	}
+	if tender.BidDeadline == nil || tender.RevealDeadline == nil {
+		return ErrInvalidDeadlines
+	}
+	bid, reveal := tender.BidDeadline.UTC(), tender.RevealDeadline.UTC()
+	if !bid.After(now) || !reveal.After(bid) {
+		return ErrInvalidDeadlines
+	}
+	tender.BidDeadline, tender.RevealDeadline = &bid, &reveal
+	return nil
+}
+
+// checkSealedBid не дает подать или изменить предложение закрытого тендера
+// после окончания приема; для открытых тендеров обязательство не хранится.
+func checkSealedBid(tender entities.Tender, bid *entities.Bid, creating bool, now time.Time) error {
+	bid.Price, bid.Salt = "", ""
+	if !tender.Sealed {
+		bid.Commitment = ""
+		return nil
+	}
+	if !now.Before(*tender.BidDeadline) {
+		return ErrBiddingClosed
+	}
+	if (creating || bid.Commitment != "") && !commitmentRe.MatchString(bid.Commitment) {
+		return ErrInvalidCommitment
+	}
+	return nil
+}
+
+// RevealBid раскрывает цену предложения закрытого тендера. Если цена и соль
+// не совпадают с обязательством, предложение сразу дисквалифицируется.
+func (s *BidService) RevealBid(ctx context.Context, id string, price string, salt string, username string) (entities.Bid, error) {
+	ctx, span := tracer.Start(ctx, "BidService.RevealBid")
+	defer span.End()
+	if !priceRe.MatchString(price) || len(salt) < minSaltLength {
+		return entities.Bid{}, ErrInvalidReveal
+	}
+	actorId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	var (
+		res      entities.Bid
+		mismatch bool
+	)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// параллельное раскрытие, отзыв или решение ждут конца транзакции
+		before, err := s.lockBid(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before.AuthorID != actorId {
+			return ErrNoAccess
+		}
+		tender, err := s.tender.GetByID(ctx, before.TenderID)
+		if err != nil {
+			return err
+		}
+		if !tender.Sealed {
+			return ErrNotSealed
+		}
+		now := time.Now().UTC()
+		if now.Before(*tender.BidDeadline) || !now.Before(*tender.RevealDeadline) {
+			return ErrRevealWindow
+		}
+		if before.RevealedAt != nil || before.Status == entities.BidStatusDisqualified {
+			return ErrAlreadyRevealed
+		}
+		if before.Status != entities.BidStatusPublished {
+			return ErrNotRevealable
+		}
+		if entities.BidCommitment(price, salt) != before.Commitment {
+			// дисквалификация фиксируется, поэтому ошибка возвращается после транзакции
+			mismatch = true
+			return s.disqualify(ctx, before, actorId, "раскрытие не совпало с обязательством")
+		}
+		res, err = s.repo.Reveal(ctx, id, price, salt)
+		if err != nil {
+			return err
+		}
+		res.TenderID = before.TenderID
+		err = s.record(ctx, id, before.TenderID, entities.AuditActionReveal, actorId, before, res)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, s.events, entities.EventBidRevealed, tender.OrganizationID, before.TenderID, id, res)
+	})
+	if err != nil {
+		return entities.Bid{}, err
+	}
+	if mismatch {
+		metrics.BidDecisions.WithLabelValues(string(entities.BidStatusDisqualified)).Inc()
+		return entities.Bid{}, ErrCommitmentMismatch
+	}
+	return res, nil
+}
+
+// DisqualifyUnrevealed дисквалифицирует предложения, не раскрытые до
+// reveal_deadline. Вызывается фоновым воркером.
+func (s *BidService) DisqualifyUnrevealed(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "BidService.DisqualifyUnrevealed")
+	defer span.End()
+	bids, err := s.repo.GetUnrevealed(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, bid := range bids {
+		var disqualified bool
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			// пока шел обход, предложение могли отозвать или раскрыть
+			before, err := s.lockBid(ctx, bid.ID)
+			if err != nil {
+				return err
+			}
+			if before.Status != entities.BidStatusPublished || before.RevealedAt != nil {
+				return nil
+			}
+			disqualified = true
+			return s.disqualify(ctx, before, "", "предложение не раскрыто до окончания срока")
+		})
+		if err != nil {
+			return count, err
+		}
+		if disqualified {
+			metrics.BidDecisions.WithLabelValues(string(entities.BidStatusDisqualified)).Inc()
+			count++
+		}
+	}
+	return count, nil
+}
+
+// lockBid перечитывает предложение под блокировкой строки. Вызывается в
+// транзакции.
+func (s *BidService) lockBid(ctx context.Context, id string) (entities.Bid, error) {
+	if err := s.repo.Lock(ctx, id); err != nil {
+		return entities.Bid{}, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// disqualify переводит заблокированное предложение в disqualified с записью
+// в журнал и событием. Вызывается в транзакции.
+func (s *BidService) disqualify(ctx context.Context, before entities.Bid, actorId string, reason string) error {
+	res, err := s.repo.ChangeBidStatus(ctx, entities.BidStatusDisqualified, before.ID)
+	if err != nil {
+		return err
+	}
+	res.TenderID = before.TenderID
+	err = s.recordReason(ctx, before.ID, before.TenderID, entities.AuditActionDisqualify, actorId, reason, before, res)
+	if err != nil {
+		return err
+	}
+	return s.publish(ctx, entities.EventBidDisqualified, res)
+}
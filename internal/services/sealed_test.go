@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+func TestValidateSealed(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	msk := time.FixedZone("MSK", 3*60*60)
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d).In(msk)
+		return &v
+	}
+	for _, tc := range []struct {
+		name   string
+		tender entities.Tender
+		err    error
+	}{
+		{"open", entities.Tender{BidDeadline: at(time.Hour)}, nil},
+		{"no deadlines", entities.Tender{Sealed: true}, ErrInvalidDeadlines},
+		{"no reveal deadline", entities.Tender{Sealed: true, BidDeadline: at(time.Hour)}, ErrInvalidDeadlines},
+		{"bid deadline passed", entities.Tender{Sealed: true, BidDeadline: at(0), RevealDeadline: at(time.Hour)}, ErrInvalidDeadlines},
+		{"reveal before bid", entities.Tender{Sealed: true, BidDeadline: at(2 * time.Hour), RevealDeadline: at(time.Hour)}, ErrInvalidDeadlines},
+		{"sealed", entities.Tender{Sealed: true, BidDeadline: at(time.Hour), RevealDeadline: at(2 * time.Hour)}, nil},
+	} {
+		tender := tc.tender
+		err := validateSealed(&tender, now)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !tender.Sealed && (tender.BidDeadline != nil || tender.RevealDeadline != nil) {
+			t.Errorf("%s: deadlines kept on an open tender", tc.name)
+		}
+		if tender.Sealed && (tender.BidDeadline.Location() != time.UTC || !tender.BidDeadline.Equal(*tc.tender.BidDeadline)) {
+			t.Errorf("%s: bid deadline %v, want the same instant in UTC", tc.name, tender.BidDeadline)
+		}
+	}
+}
+
+func TestCheckSealedBid(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	deadline := now.Add(time.Hour)
+	sealed := entities.Tender{Sealed: true, BidDeadline: &deadline}
+	commitment := entities.BidCommitment("1500.00", "соль-не-короче-16")
+	for _, tc := range []struct {
+		name     string
+		tender   entities.Tender
+		bid      entities.Bid
+		creating bool
+		now      time.Time
+		err      error
+	}{
+		{"open tender", entities.Tender{}, entities.Bid{Commitment: commitment}, true, now, nil},
+		{"create", sealed, entities.Bid{Commitment: commitment}, true, now, nil},
+		{"create without commitment", sealed, entities.Bid{}, true, now, ErrInvalidCommitment},
+		{"uppercase commitment", sealed, entities.Bid{Commitment: strings.ToUpper(commitment)}, true, now, ErrInvalidCommitment},
+		{"short commitment", sealed, entities.Bid{Commitment: commitment[:63]}, true, now, ErrInvalidCommitment},
+		{"edit keeps commitment", sealed, entities.Bid{}, false, now, nil},
+		{"edit with bad commitment", sealed, entities.Bid{Commitment: "00"}, false, now, ErrInvalidCommitment},
+		{"after deadline", sealed, entities.Bid{Commitment: commitment}, true, deadline, ErrBiddingClosed},
+	} {
+		bid := tc.bid
+		bid.Price, bid.Salt = "1500.00", "соль-не-короче-16"
+		err := checkSealedBid(tc.tender, &bid, tc.creating, tc.now)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+		// цена и соль приходят только при раскрытии
+		if bid.Price != "" || bid.Salt != "" {
+			t.Errorf("%s: price and salt kept", tc.name)
+		}
+		if !tc.tender.Sealed && bid.Commitment != "" {
+			t.Errorf("%s: commitment kept on an open tender", tc.name)
+		}
+	}
+}
+
+// newRevealService — закрытый тендер t1 в окне раскрытия и предложения
+// автора author в разных статусах.
+func newRevealService() (Bid, *bidRepo) {
+	commitment := entities.BidCommitment("1500.00", "соль-не-короче-16")
+	bid := func(id string, status entities.BidStatus) entities.Bid {
+		return entities.Bid{ID: id, TenderID: "t1", AuthorID: "author", Status: status, Commitment: commitment}
+	}
+	bids := &bidRepo{bids: map[string]entities.Bid{
+		"published": bid("published", entities.BidStatusPublished),
+		"created":   bid("created", entities.BidStatusCreated),
+		"canceled":  bid("canceled", entities.BidStatusCanceled),
+		"approved":  bid("approved", entities.BidStatusApproved),
+	}}
+	now := time.Now().UTC()
+	bidDeadline, revealDeadline := now.Add(-time.Hour), now.Add(time.Hour)
+	tenders := &tenderRepo{tenders: map[string]entities.Tender{
+		"t1": {ID: "t1", OrganizationID: "o1", Status: entities.TenderStatusPublished, Sealed: true,
+			BidDeadline: &bidDeadline, RevealDeadline: &revealDeadline},
+	}}
+	return NewBidService(bids, userIDs{}, tenders, &auditRepo{}, nil, noTx{}, nil, deciders{tender: tenders}), bids
+}
+
+func TestRevealBid(t *testing.T) {
+	ctx := context.Background()
+	s, bids := newRevealService()
+
+	if _, err := s.RevealBid(ctx, "published", "1500.00", "соль-не-короче-16", "stranger"); !errors.Is(err, ErrNoAccess) {
+		t.Errorf("stranger: got %v, want %v", err, ErrNoAccess)
+	}
+	for _, id := range []string{"created", "canceled", "approved"} {
+		if _, err := s.RevealBid(ctx, id, "1500.00", "соль-не-короче-16", "author"); !errors.Is(err, ErrNotRevealable) {
+			t.Errorf("%s bid: got %v, want %v", id, err, ErrNotRevealable)
+		}
+		if bids.bids[id].RevealedAt != nil {
+			t.Errorf("%s bid revealed", id)
+		}
+	}
+	bid, err := s.RevealBid(ctx, "published", "1500.00", "соль-не-короче-16", "author")
+	if err != nil || bid.Price != "1500.00" || bid.RevealedAt == nil {
+		t.Fatalf("reveal: got %+v, %v", bid, err)
+	}
+	if _, err := s.RevealBid(ctx, "published", "1500.00", "соль-не-короче-16", "author"); !errors.Is(err, ErrAlreadyRevealed) {
+		t.Errorf("second reveal: got %v, want %v", err, ErrAlreadyRevealed)
+	}
+}
+
+func TestRevealBidMismatchDisqualifies(t *testing.T) {
+	ctx := context.Background()
+	s, bids := newRevealService()
+	if _, err := s.RevealBid(ctx, "published", "1499.00", "соль-не-короче-16", "author"); !errors.Is(err, ErrCommitmentMismatch) {
+		t.Fatalf("got %v, want %v", err, ErrCommitmentMismatch)
+	}
+	if status := bids.bids["published"].Status; status != entities.BidStatusDisqualified {
+		t.Fatalf("status %s, want disqualified", status)
+	}
+	// отозванное предложение несовпадение не дисквалифицирует
+	if _, err := s.RevealBid(ctx, "canceled", "1499.00", "соль-не-короче-16", "author"); !errors.Is(err, ErrNotRevealable) {
+		t.Fatalf("canceled: got %v, want %v", err, ErrNotRevealable)
+	}
+	if status := bids.bids["canceled"].Status; status != entities.BidStatusCanceled {
+		t.Fatalf("canceled bid became %s", status)
+	}
+}
+
+func TestDisqualifyUnrevealedRechecksStatus(t *testing.T) {
+	s, bids := newRevealService()
+	n, err := s.DisqualifyUnrevealed(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// из четырех нераскрытых подано и еще не решено только одно
+	if n != 1 || bids.bids["published"].Status != entities.BidStatusDisqualified {
+		t.Fatalf("disqualified %d, published bid is %s", n, bids.bids["published"].Status)
+	}
+	for _, id := range []string{"created", "canceled", "approved"} {
+		if bids.bids[id].Status == entities.BidStatusDisqualified {
+			t.Errorf("%s bid disqualified", id)
+		}
+	}
+}
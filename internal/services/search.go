@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrSearchNotFound  = repositories.ErrSearchNotFound
+	ErrInvalidFilter   = entities.ErrInvalidFilter
+	ErrInvalidSearch   = errors.New("у поиска должны быть название до 100 символов, хотя бы один фильтр и канал notification или email")
+	ErrTooManySearches = errors.New("достигнут предел сохраненных поисков")
+)
+
+const maxSearches = 20
+
+type Search interface {
+	GetSearches(ctx context.Context, username string) (entities.SavedSearchList, error)
+	CreateSearch(ctx context.Context, search entities.SavedSearch, username string) (entities.SavedSearch, error)
+	DeleteSearch(ctx context.Context, id string, username string) error
+	WatchTender(ctx context.Context, tenderId string, watch bool, username string) error
+	GetWatched(ctx context.Context, username string, limit int, offset int) (entities.TenderList, error)
+}
+
+type SearchService struct {
+	repo   repositories.Search
+	user   repositories.User
+	tender repositories.Tender
+	authz  Authorizer
+}
+
+func NewSearchService(repo repositories.Search, user repositories.User, tender repositories.Tender, authz Authorizer) Search {
+	return &SearchService{
+		repo:   repo,
+		user:   user,
+		tender: tender,
+		authz:  authz,
+	}
+}
+
+func (s *SearchService) GetSearches(ctx context.Context, username string) (entities.SavedSearchList, error) {
+	ctx, span := tracer.Start(ctx, "SearchService.GetSearches")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByUser(ctx, userId)
+}
+
+// CreateSearch сохраняет поиск с теми же фильтрами, что у GET /tenders.
+// Пустой канал — notification.
+func (s *SearchService) CreateSearch(ctx context.Context, search entities.SavedSearch, username string) (entities.SavedSearch, error) {
+	ctx, span := tracer.Start(ctx, "SearchService.CreateSearch")
+	defer span.End()
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Channel == "" {
+		search.Channel = entities.SearchChannelNotification
+	}
+	var channel entities.SearchChannel
+	channel.Scan(string(search.Channel))
+	if search.Name == "" || utf8.RuneCountInString(search.Name) > 100 || channel == "" {
+		return entities.SavedSearch{}, ErrInvalidSearch
+	}
+	if err := search.TenderFilter.Normalize(); err != nil {
+		return entities.SavedSearch{}, err
+	}
+	if search.TenderFilter.Empty() {
+		return entities.SavedSearch{}, ErrInvalidSearch
+	}
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return entities.SavedSearch{}, err
+	}
+	existing, err := s.repo.GetByUser(ctx, userId)
+	if err != nil {
+		return entities.SavedSearch{}, err
+	}
+	if len(existing) >= maxSearches {
+		return entities.SavedSearch{}, ErrTooManySearches
+	}
+	return s.repo.Create(ctx, userId, search)
+}
+
+func (s *SearchService) DeleteSearch(ctx context.Context, id string, username string) error {
+	ctx, span := tracer.Start(ctx, "SearchService.DeleteSearch")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, userId)
+}
+
+// WatchTender подписывает пользователя на все изменения тендера или
+// отписывает от них. Следить можно только за видимым тендером.
+func (s *SearchService) WatchTender(ctx context.Context, tenderId string, watch bool, username string) error {
+	ctx, span := tracer.Start(ctx, "SearchService.WatchTender")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !watch {
+		return s.repo.Unwatch(ctx, userId, tenderId)
+	}
+	tender, err := s.tender.GetByID(ctx, tenderId)
+	if err != nil {
+		return err
+	}
+	if err := s.authz.ViewTender(ctx, userId, tender); err != nil {
+		return err
+	}
+	return s.repo.Watch(ctx, userId, tenderId)
+}
+
+func (s *SearchService) GetWatched(ctx context.Context, username string, limit int, offset int) (entities.TenderList, error) {
+	ctx, span := tracer.Start(ctx, "SearchService.GetWatched")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetWatched(ctx, userId, limit, offset)
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+const (
+	streamBuffer = 64
+	// streamBacklog — сколько пропущенных событий досылается при
+	// переподключении
+	streamBacklog = 1000
+)
+
+// ErrStreamBacklog — клиент пропустил больше streamBacklog событий.
+var ErrStreamBacklog = errors.New("пропущено слишком много событий: загрузите тендер заново и подключитесь без Last-Event-ID")
+
+// Subscriber выдает новые события тендера; реализуется outbox.Hub.
+type Subscriber interface {
+	Subscribe(tenderId string, buffer int) (<-chan entities.OutboxEntry, func())
+}
+
+type Stream interface {
+	Subscribe(ctx context.Context, tenderId string, lastEventId int64, username string) (<-chan entities.StreamEvent, error)
+}
+
+type StreamService struct {
+	outbox repositories.Outbox
+	tender repositories.Tender
+	bid    repositories.Bid
+	user   repositories.User
+	authz  Authorizer
+	hub    Subscriber
+}
+
+func NewStreamService(outbox repositories.Outbox, tender repositories.Tender, bid repositories.Bid,
+	user repositories.User, authz Authorizer, hub Subscriber) Stream {
+	return &StreamService{
+		outbox: outbox,
+		tender: tender,
+		bid:    bid,
+		user:   user,
+		authz:  authz,
+		hub:    hub,
+	}
+}
+
+// streamViewer — подписчик. События тендера он видит целиком, события
+// предложений — если видит предложение (Authorizer.ViewBid), а о
+// остальных только факт подачи и выбор победителя.
+type streamViewer struct {
+	userId string
+}
+
+// Subscribe проверяет доступ и возвращает события тендера после lastEventId
+// (0 — только новые). Канал закрывается при отмене ctx или отключении
+// отставшего подписчика; клиенту стоит переподключиться с Last-Event-ID.
+func (s *StreamService) Subscribe(ctx context.Context, tenderId string, lastEventId int64, username string) (<-chan entities.StreamEvent, error) {
+	streamCtx := ctx
+	ctx, span := tracer.Start(ctx, "StreamService.Subscribe")
+	defer span.End()
+	viewer, err := s.viewer(ctx, tenderId, username)
+	if err != nil {
+		return nil, err
+	}
+	// подписка до чтения пропущенного, чтобы не потерять события между ними;
+	// повторы отсекаются по id
+	live, cancel := s.hub.Subscribe(tenderId, streamBuffer)
+	var backlog []entities.OutboxEntry
+	if lastEventId > 0 {
+		backlog, err = s.outbox.GetAfter(ctx, lastEventId, tenderId, streamBacklog+1)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if len(backlog) > streamBacklog {
+			cancel()
+			return nil, ErrStreamBacklog
+		}
+	}
+	out := make(chan entities.StreamEvent)
+	go func() {
+		defer close(out)
+		defer cancel()
+		ctx := streamCtx
+		// Hub может прислать событие с id меньше уже отправленных (см.
+		// outbox.Hub), поэтому повторы ищутся среди досланного, а не по
+		// последнему id
+		sent := make(map[int64]bool, len(backlog))
+		send := func(e entities.OutboxEntry) bool {
+			if sent[e.ID] {
+				return true
+			}
+			event, ok, err := s.view(ctx, viewer, e)
+			if err != nil {
+				return false
+			}
+			if !ok {
+				return true
+			}
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, e := range backlog {
+			if !send(e) {
+				return
+			}
+			sent[e.ID] = true
+		}
+		for {
+			select {
+			case e, ok := <-live:
+				if !ok || !send(e) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (s *StreamService) viewer(ctx context.Context, tenderId string, username string) (streamViewer, error) {
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return streamViewer{}, err
+	}
+	tender, err := s.tender.GetByID(ctx, tenderId)
+	if err != nil {
+		return streamViewer{}, err
+	}
+	if err := s.authz.ViewTender(ctx, userId, tender); err != nil {
+		return streamViewer{}, err
+	}
+	return streamViewer{userId: userId}, nil
+}
+
+// view готовит событие для подписчика; false — событие ему не показывается.
+func (s *StreamService) view(ctx context.Context, viewer streamViewer, e entities.OutboxEntry) (entities.StreamEvent, bool, error) {
+	event := entities.StreamEvent{
+		ID:         e.ID,
+		Type:       e.Event.Type,
+		TenderID:   e.Event.TenderID,
+		BidID:      e.Event.BidID,
+		OccurredAt: e.Event.OccurredAt,
+	}
+	var data struct {
+		Status     string `json:"status"`
+		AuthorID   string `json:"author_id"`
+		AuthorType string `json:"author_type"`
+	}
+	if err := json.Unmarshal(e.Event.Data, &data); err != nil {
+		return entities.StreamEvent{}, false, err
+	}
+	event.Status = data.Status
+	if e.Event.Type == entities.EventBidCreated {
+		count, err := s.bid.CountByTender(ctx, e.Event.TenderID)
+		if err != nil {
+			return entities.StreamEvent{}, false, err
+		}
+		event.BidCount = &count
+	}
+	if e.Event.BidID == "" {
+		event.Data = e.Event.Data
+		return event, true, nil
+	}
+	bid := entities.Bid{AuthorID: data.AuthorID, AuthorType: data.AuthorType, TenderID: e.Event.TenderID}
+	bid.Status.Scan(data.Status)
+	err := s.authz.ViewBid(ctx, viewer.userId, bid)
+	if err == nil {
+		event.Data = e.Event.Data
+		return event, true, nil
+	}
+	if !errors.Is(err, ErrNoAccess) {
+		return entities.StreamEvent{}, false, err
+	}
+	switch e.Event.Type {
+	case entities.EventBidCreated, entities.EventBidApproved:
+		event.BidID, event.Status = "", ""
+		return event, true, nil
+	}
+	return entities.StreamEvent{}, false, nil
+}
@@ -3,7 +3,9 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/metrics"
 	"zadanie-6105/internal/repositories"
 	"zadanie-6105/internal/repositories/entities"
 )
@@ -11,87 +13,275 @@ import (
 var (
 	ErrNotResponsible = errors.New("пользователь не связан с организацией")
 	ErrNoAccess       = errors.New("нет доступа, пользователь не отвественен за тендер")
+	ErrUserNotFound   = repositories.ErrUserNotFound
+	ErrTenderNotFound = repositories.ErrTenderNotFound
+	ErrInvalidBudget  = errors.New("budget — неотрицательное число с точностью до копеек")
 )
 
 type Tender interface {
-	GetTenderList(ctx context.Context, params operation.TenderListParams) (entities.TenderList, error)
+	GetTenderList(ctx context.Context, params operation.TenderListParams, username string) (entities.TenderList, error)
 	CreateTender(ctx context.Context, tender entities.Tender) (entities.Tender, error)
 	GetTenderByUser(ctx context.Context, creator string, params operation.TenderListParams) (entities.TenderList, error)
 	GetTenderStatus(ctx context.Context, id string, username string) (entities.TenderStatus, error)
 	ChangeTenderStatus(ctx context.Context, status entities.TenderStatus, id string, username string) (entities.Tender, error)
 	EditTender(ctx context.Context, tender entities.Tender, id string, username string) (entities.Tender, error)
+	ForceTenderStatus(ctx context.Context, status entities.TenderStatus, id string, actorId string, reason string) (entities.Tender, error)
+	RemindDeadlines(ctx context.Context, within time.Duration) (int, error)
 }
 
 type TenderService struct {
-	repo repositories.Tender
-	user repositories.User
+	repo   repositories.Tender
+	user   repositories.User
+	audit  repositories.Audit
+	tx     repositories.TxManager
+	events Events
+	authz  Authorizer
 }
 
-func NewTenderService(repo repositories.Tender, user repositories.User) Tender {
+// events пишутся в той же транзакции, что и изменение, поэтому это должен
+// быть NewOutboxPublisher, а не внешний получатель.
+func NewTenderService(repo repositories.Tender, user repositories.User, audit repositories.Audit,
+	tx repositories.TxManager, events Events, authz Authorizer) Tender {
 	return &TenderService{
-		repo: repo,
-		user: user,
+		repo:   repo,
+		user:   user,
+		audit:  audit,
+		tx:     tx,
+		events: events,
+		authz:  authz,
 	}
 }
 
-func (s *TenderService) GetTenderList(ctx context.Context, params operation.TenderListParams) (entities.TenderList, error) {
-	return s.repo.GetTenderList(ctx, params)
+// GetTenderList без username отдает только опубликованные и закрытые
+// тендеры, с ним — еще и неопубликованные тендеры организаций сотрудника.
+func (s *TenderService) GetTenderList(ctx context.Context, params operation.TenderListParams, username string) (entities.TenderList, error) {
+	ctx, span := tracer.Start(ctx, "TenderService.GetTenderList")
+	defer span.End()
+	viewer, err := s.authz.Viewer(ctx, username)
+	if err != nil {
+		return entities.TenderList{}, err
+	}
+	return s.repo.GetTenderList(ctx, params, viewer)
 }
 
 func (s *TenderService) CreateTender(ctx context.Context, tender entities.Tender) (entities.Tender, error) {
-	organizationId, err := s.user.IsResponsible(ctx, tender.CreatorUsername) //"" - значит юзер не остветсвенен за организацию
+	ctx, span := tracer.Start(ctx, "TenderService.CreateTender")
+	defer span.End()
+	organizationId := tender.OrganizationID
+	err := s.authz.Authorize(ctx, tender.CreatorUsername, organizationId, entities.PermTenderCreate)
 	if err != nil {
 		return entities.Tender{}, err
 	}
-	if organizationId == "" {
-		return entities.Tender{}, ErrNotResponsible
+	// Логин автора приходит в теле, а не в запросе: к базе обращаемся от его имени.
+	viewer, err := s.authz.Viewer(ctx, tender.CreatorUsername)
+	if err != nil {
+		return entities.Tender{}, err
 	}
-	if organizationId != tender.OrganizationID {
-		return entities.Tender{}, ErrNoAccess
+	ctx = repositories.WithViewer(ctx, viewer)
+	if err := validateSealed(&tender, time.Now().UTC()); err != nil {
+		return entities.Tender{}, err
+	}
+	if tender.Budget != "" && !entities.ValidBudget(tender.Budget) {
+		return entities.Tender{}, ErrInvalidBudget
+	}
+	var res entities.Tender
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		res, err = s.repo.Create(ctx, tender)
+		if err != nil {
+			return err
+		}
+		res.OrganizationID, res.CreatorUsername = tender.OrganizationID, tender.CreatorUsername
+		err = s.record(ctx, res.ID, entities.AuditActionCreate, tender.CreatorUsername, organizationId, nil, res)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, s.events, entities.EventTenderCreated, organizationId, res.ID, "", res)
+	})
+	if err != nil {
+		return entities.Tender{}, err
 	}
-	return s.repo.Create(ctx, tender)
+	metrics.TendersCreated.Inc()
+	return res, nil
 }
 
 func (s *TenderService) GetTenderByUser(ctx context.Context, creator string, params operation.TenderListParams) (entities.TenderList, error) {
-	return s.repo.GetByUser(ctx, creator, params)
+	ctx, span := tracer.Start(ctx, "TenderService.GetTenderByUser")
+	defer span.End()
+	viewer, err := s.authz.Viewer(ctx, creator)
+	if err != nil {
+		return entities.TenderList{}, err
+	}
+	return s.repo.GetByUser(ctx, creator, params, viewer)
 }
 
 func (s *TenderService) GetTenderStatus(ctx context.Context, id string, username string) (entities.TenderStatus, error) {
-	return s.repo.GetTenderStatus(ctx, id)
+	ctx, span := tracer.Start(ctx, "TenderService.GetTenderStatus")
+	defer span.End()
+	userId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	tender, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := s.authz.ViewTender(ctx, userId, tender); err != nil {
+		return "", err
+	}
+	return tender.Status, nil
 }
 
 func (s *TenderService) ChangeTenderStatus(ctx context.Context, status entities.TenderStatus, id string, username string) (entities.Tender, error) {
-	organizationId, err := s.user.IsResponsible(ctx, username) //"" - значит юзер не остветсвенен за организацию
+	ctx, span := tracer.Start(ctx, "TenderService.ChangeTenderStatus")
+	defer span.End()
+	organizationId, err := s.authz.AuthorizeTender(ctx, username, id, entities.PermTenderEdit)
 	if err != nil {
 		return entities.Tender{}, err
 	}
-	if organizationId == "" {
-		return entities.Tender{}, ErrNotResponsible
-	}
-	tenderOrganizationId, err := s.repo.CheckTenderOrganization(ctx, id)
+	var (
+		res entities.Tender
+		// published — тендер опубликован этим вызовом, а не был опубликован раньше
+		published bool
+	)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		res, err = s.repo.ChangeTenderStatus(ctx, status, id)
+		if err != nil {
+			return err
+		}
+		err = s.record(ctx, id, entities.AuditActionStatusChange, username, organizationId, before, res)
+		if err != nil {
+			return err
+		}
+		if before.Status == status {
+			return nil
+		}
+		event := entities.EventTenderStatusChanged
+		if status == entities.TenderStatusPublished {
+			event, published = entities.EventTenderPublished, true
+		}
+		return publish(ctx, s.events, event, organizationId, id, "", res)
+	})
 	if err != nil {
 		return entities.Tender{}, err
 	}
-	if tenderOrganizationId != organizationId {
-		return entities.Tender{}, ErrNoAccess
+	if published {
+		metrics.TendersPublished.Inc()
 	}
-	return s.repo.ChangeTenderStatus(ctx, status, id)
+	return res, nil
 }
 
-func (s *TenderService) EditTender(ctx context.Context, tender entities.Tender, id string, username string) (entities.Tender, error) {
-	organizationId, err := s.user.IsResponsible(ctx, username) //0 - значит юзер не остветсвенен за организацию
+// ForceTenderStatus меняет статус в обход проверки прав — для tenderctl.
+// Причина попадает в журнал, а событие в outbox, как при обычной смене
+// статуса; actorId может быть пустым.
+func (s *TenderService) ForceTenderStatus(ctx context.Context, status entities.TenderStatus, id string, actorId string, reason string) (entities.Tender, error) {
+	ctx, span := tracer.Start(ctx, "TenderService.ForceTenderStatus")
+	defer span.End()
+	var res entities.Tender
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		res, err = s.repo.ChangeTenderStatus(ctx, status, id)
+		if err != nil {
+			return err
+		}
+		entry, err := NewAuditEntry(ctx, entities.AuditEntityTender, id, id, entities.AuditActionForcedStatusChange,
+			actorId, before.OrganizationID, before, res)
+		if err != nil {
+			return err
+		}
+		entry.Reason = reason
+		if err := s.audit.Create(ctx, entry); err != nil {
+			return err
+		}
+		if before.Status == status {
+			return nil
+		}
+		event := entities.EventTenderStatusChanged
+		if status == entities.TenderStatusPublished {
+			event = entities.EventTenderPublished
+		}
+		return publish(ctx, s.events, event, before.OrganizationID, id, "", res)
+	})
 	if err != nil {
 		return entities.Tender{}, err
 	}
-	if organizationId == "" {
-		return entities.Tender{}, ErrNotResponsible
+	return res, nil
+}
+
+func (s *TenderService) EditTender(ctx context.Context, tender entities.Tender, id string, username string) (entities.Tender, error) {
+	ctx, span := tracer.Start(ctx, "TenderService.EditTender")
+	defer span.End()
+	if tender.Budget != "" && !entities.ValidBudget(tender.Budget) {
+		return entities.Tender{}, ErrInvalidBudget
+	}
+	organizationId, err := s.authz.AuthorizeTender(ctx, username, id, entities.PermTenderEdit)
+	if err != nil {
+		return entities.Tender{}, err
 	}
-	tenderOrganizationId, err := s.repo.CheckTenderOrganization(ctx, id)
+	var res entities.Tender
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		res, err = s.repo.EditTender(ctx, tender, id)
+		if err != nil {
+			return err
+		}
+		err = s.record(ctx, id, entities.AuditActionEdit, username, organizationId, before, res)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, s.events, entities.EventTenderEdited, organizationId, id, "", res)
+	})
 	if err != nil {
 		return entities.Tender{}, err
 	}
-	if tenderOrganizationId != organizationId {
-		return entities.Tender{}, ErrNoAccess
+	return res, nil
+}
+
+// RemindDeadlines публикует tender.deadline_approaching для закрытых
+// тендеров, до bid_deadline которых осталось меньше within. О каждом тендере
+// напоминание отправляется один раз. Вызывается фоновым воркером.
+func (s *TenderService) RemindDeadlines(ctx context.Context, within time.Duration) (int, error) {
+	ctx, span := tracer.Start(ctx, "TenderService.RemindDeadlines")
+	defer span.End()
+	var n int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tenders, err := s.repo.ClaimDeadlineReminders(ctx, within)
+		if err != nil {
+			return err
+		}
+		for _, t := range tenders {
+			err := publish(ctx, s.events, entities.EventTenderDeadline, t.OrganizationID, t.ID, "", t)
+			if err != nil {
+				return err
+			}
+		}
+		n = len(tenders)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *TenderService) record(ctx context.Context, id string, action entities.AuditAction,
+	username string, organizationId string, before any, after any) error {
+	actorId, err := s.user.GetUserIDByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	entry, err := NewAuditEntry(ctx, entities.AuditEntityTender, id, id, action, actorId, organizationId, before, after)
+	if err != nil {
+		return err
 	}
-	return s.repo.EditTender(ctx, tender, id)
+	return s.audit.Create(ctx, entry)
 }
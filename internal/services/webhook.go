@@ -0,0 +1,360 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+	"zadanie-6105/internal/metrics"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+var (
+	ErrInvalidWebhook   = errors.New("url должен быть http(s), а события — из известных типов tender.* и bid.*")
+	ErrWebhookAddress   = errors.New("адрес вебхука должен быть публичным: локальные и внутренние сети запрещены")
+	ErrWebhookNotFound  = repositories.ErrWebhookNotFound
+	ErrDeliveryNotFound = repositories.ErrDeliveryNotFound
+)
+
+const (
+	webhookTimeout     = 10 * time.Second
+	webhookLease       = time.Minute
+	webhookBatch       = 20
+	webhookMaxAttempts = 8
+	webhookBackoff     = 30 * time.Second
+	webhookMaxBackoff  = time.Hour
+)
+
+// Events публикует доменные события подписчикам.
+type Events interface {
+	Publish(ctx context.Context, event entities.Event) error
+}
+
+type Webhook interface {
+	Events
+	CreateWebhook(ctx context.Context, hook entities.Webhook, username string) (entities.Webhook, error)
+	GetWebhooks(ctx context.Context, organizationId string, username string) (entities.WebhookList, error)
+	DeleteWebhook(ctx context.Context, id string, username string) error
+	GetDeliveries(ctx context.Context, id string, limit int, offset int, username string) (entities.WebhookDeliveryList, error)
+	Redeliver(ctx context.Context, deliveryId string, username string) (entities.WebhookDelivery, error)
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+type WebhookService struct {
+	repo   repositories.Webhook
+	authz  Authorizer
+	client *http.Client
+}
+
+// client == nil — используется клиент с таймаутом webhookTimeout, который
+// соединяется только с публичными адресами (см. publicAddress).
+func NewWebhookService(repo repositories.Webhook, authz Authorizer, client *http.Client) Webhook {
+	if client == nil {
+		client = newWebhookClient()
+	}
+	return &WebhookService{
+		repo:   repo,
+		authz:  authz,
+		client: client,
+	}
+}
+
+// NewEvent собирает событие, data сериализуется в JSON.
+func NewEvent(typ entities.EventType, organizationId string, tenderId string, bidId string, data any) (entities.Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return entities.Event{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return entities.Event{}, err
+	}
+	return entities.Event{
+		ID:             hex.EncodeToString(id),
+		Type:           typ,
+		OrganizationID: organizationId,
+		TenderID:       tenderId,
+		BidID:          bidId,
+		OccurredAt:     time.Now().UTC(),
+		Data:           raw,
+	}, nil
+}
+
+// publish собирает и публикует событие; events == nil — публикация отключена.
+func publish(ctx context.Context, events Events, typ entities.EventType, organizationId string,
+	tenderId string, bidId string, data any) error {
+	if events == nil {
+		return nil
+	}
+	event, err := NewEvent(typ, organizationId, tenderId, bidId, data)
+	if err != nil {
+		return err
+	}
+	return events.Publish(ctx, event)
+}
+
+// Publish ставит событие в очередь доставки каждому подписанному вебхуку организации.
+func (s *WebhookService) Publish(ctx context.Context, event entities.Event) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.Publish")
+	defer span.End()
+	// Вебхуки принадлежат организации тендера, а неподанные предложения ей
+	// не видны.
+	if event.BidID != "" {
+		var data struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return err
+		}
+		if !entities.BidStatus(data.Status).Submitted() {
+			return nil
+		}
+	}
+	hooks, err := s.repo.GetSubscribed(ctx, event.OrganizationID, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		_, err := s.repo.CreateDelivery(ctx, entities.WebhookDelivery{
+			WebhookID: hook.ID,
+			Event:     event.Type,
+			EventID:   event.ID,
+			Payload:   payload,
+		})
+		// повторная публикация того же события не создает вторую доставку
+		if err != nil && !errors.Is(err, repositories.ErrDeliveryExists) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, hook entities.Webhook, username string) (entities.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+	if hook.OrganizationID == "" {
+		return entities.Webhook{}, ErrInvalidWebhook
+	}
+	if err := s.authz.Authorize(ctx, username, hook.OrganizationID, entities.PermWebhookManage); err != nil {
+		return entities.Webhook{}, err
+	}
+	if err := checkWebhookURL(ctx, hook.URL); err != nil {
+		return entities.Webhook{}, err
+	}
+	for _, e := range hook.Events {
+		var typ entities.EventType
+		typ.Scan(string(e))
+		if typ == "" {
+			return entities.Webhook{}, ErrInvalidWebhook
+		}
+	}
+	if hook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return entities.Webhook{}, err
+		}
+		hook.Secret = hex.EncodeToString(secret)
+	}
+	res, err := s.repo.Create(ctx, hook)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+	res.Secret = hook.Secret
+	return res, nil
+}
+
+func (s *WebhookService) GetWebhooks(ctx context.Context, organizationId string, username string) (entities.WebhookList, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetWebhooks")
+	defer span.End()
+	if err := s.authz.Authorize(ctx, username, organizationId, entities.PermWebhookManage); err != nil {
+		return entities.WebhookList{}, err
+	}
+	return s.repo.GetByOrganization(ctx, organizationId)
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string, username string) error {
+	ctx, span := tracer.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+	hook, err := s.checkWebhook(ctx, id, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, hook.OrganizationID)
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, id string, limit int, offset int, username string) (entities.WebhookDeliveryList, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetDeliveries")
+	defer span.End()
+	if _, err := s.checkWebhook(ctx, id, username); err != nil {
+		return entities.WebhookDeliveryList{}, err
+	}
+	return s.repo.GetDeliveries(ctx, id, limit, offset)
+}
+
+// Redeliver ставит в очередь копию доставки; исходная запись журнала не меняется.
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryId string, username string) (entities.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Redeliver")
+	defer span.End()
+	delivery, err := s.repo.GetDelivery(ctx, deliveryId)
+	if err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	if _, err := s.checkWebhook(ctx, delivery.WebhookID, username); err != nil {
+		return entities.WebhookDelivery{}, err
+	}
+	return s.repo.CreateDelivery(ctx, entities.WebhookDelivery{
+		WebhookID: delivery.WebhookID,
+		Event:     delivery.Event,
+		Payload:   delivery.Payload,
+	})
+}
+
+// DeliverPending отправляет доставки, чья попытка подошла. Неудачные
+// повторяются с экспоненциальной задержкой, после webhookMaxAttempts
+// попыток доставка помечается failed. Вызывается фоновым воркером.
+func (s *WebhookService) DeliverPending(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.DeliverPending")
+	defer span.End()
+	deliveries, err := s.repo.ClaimDeliveries(ctx, webhookBatch, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range deliveries {
+		code, err := s.send(ctx, d)
+		if err == nil {
+			metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+			if err := s.repo.CompleteDelivery(ctx, d.ID, code); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		attempt := d.Attempts + 1
+		var retryIn time.Duration
+		if attempt < webhookMaxAttempts {
+			retryIn = webhookBackoff << (attempt - 1)
+			if retryIn > webhookMaxBackoff {
+				retryIn = webhookMaxBackoff
+			}
+			metrics.WebhookDeliveries.WithLabelValues("retry").Inc()
+		} else {
+			metrics.WebhookDeliveries.WithLabelValues("failed").Inc()
+		}
+		if err := s.repo.FailDelivery(ctx, d.ID, code, err.Error(), retryIn); err != nil {
+			return 0, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// send отправляет доставку с заголовками X-Webhook-*. Подпись — см.
+// entities.WebhookSignature.
+func (s *WebhookService) send(ctx context.Context, d entities.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", string(d.Event))
+	req.Header.Set("X-Webhook-Delivery", d.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", entities.WebhookSignature(d.Secret, timestamp, d.Payload))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// newWebhookClient создает клиент без прокси, который проверяет адрес при
+// каждом соединении: имя получателя могло начать указывать во внутреннюю
+// сеть после регистрации, а ответ — перенаправить туда.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+				return ErrWebhookAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: webhookTimeout, Transport: transport}
+}
+
+// checkWebhookURL проверяет схему и то, что все адреса хоста публичные.
+func checkWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhook
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return ErrInvalidWebhook
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr.IP) {
+			return ErrWebhookAddress
+		}
+	}
+	return nil
+}
+
+// sharedAddressSpace — 100.64.0.0/10 (RFC 6598), адреса за NAT провайдера.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicAddress сообщает, можно ли отправлять вебхук на ip: петлевые,
+// частные, link-local (в том числе 169.254.169.254 облачных метаданных),
+// групповые и неуказанные адреса запрещены.
+func publicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip))
+}
+
+// checkWebhook проверяет, что username может управлять вебхуками
+// организации, которой принадлежит вебхук id.
+func (s *WebhookService) checkWebhook(ctx context.Context, id string, username string) (entities.Webhook, error) {
+	hook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+	err = s.authz.Authorize(ctx, username, hook.OrganizationID, entities.PermWebhookManage)
+	if errors.Is(err, ErrNotResponsible) {
+		// чужой вебхук неотличим от несуществующего
+		return entities.Webhook{}, ErrWebhookNotFound
+	}
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+	return hook, nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"zadanie-6105/internal/repositories"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// webhookRepo хранит доставки в памяти и, как уникальный индекс
+// webhook_delivery_event_uniq, не дает создать вторую доставку события.
+type webhookRepo struct {
+	repositories.Webhook
+	mu        sync.Mutex
+	hooks     entities.WebhookList
+	pending   entities.WebhookDeliveryList
+	created   entities.WebhookDeliveryList
+	completed map[string]int
+	failed    map[string]string
+	retries   map[string]time.Duration
+}
+
+func newWebhookRepo(hooks ...entities.Webhook) *webhookRepo {
+	return &webhookRepo{
+		hooks:     hooks,
+		completed: map[string]int{},
+		failed:    map[string]string{},
+		retries:   map[string]time.Duration{},
+	}
+}
+
+func (r *webhookRepo) GetSubscribed(ctx context.Context, organizationId string, event entities.EventType) (entities.WebhookList, error) {
+	return r.hooks, nil
+}
+
+func (r *webhookRepo) CreateDelivery(ctx context.Context, d entities.WebhookDelivery) (entities.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.created {
+		if d.EventID != "" && c.WebhookID == d.WebhookID && c.EventID == d.EventID {
+			return entities.WebhookDelivery{}, repositories.ErrDeliveryExists
+		}
+	}
+	r.created = append(r.created, d)
+	return d, nil
+}
+
+func (r *webhookRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (entities.WebhookDeliveryList, error) {
+	res := r.pending
+	r.pending = nil
+	return res, nil
+}
+
+func (r *webhookRepo) CompleteDelivery(ctx context.Context, id string, code int) error {
+	r.completed[id] = code
+	return nil
+}
+
+func (r *webhookRepo) FailDelivery(ctx context.Context, id string, code int, reason string, retryIn time.Duration) error {
+	r.failed[id], r.retries[id] = reason, retryIn
+	return nil
+}
+
+func TestDeliverPending(t *testing.T) {
+	const secret = "секрет"
+	payload := []byte(`{"id":"e1","type":"tender.published"}`)
+	var calls int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		sig := entities.WebhookSignature(secret, r.Header.Get("X-Webhook-Timestamp"), body)
+		if r.Method != http.MethodPost || string(body) != string(payload) || r.Header.Get("X-Webhook-Signature") != sig {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("X-Webhook-Event") != "tender.published" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := newWebhookRepo()
+	delivery := entities.WebhookDelivery{Event: entities.EventTenderPublished, Payload: payload, Secret: secret}
+	ok, retry, last := delivery, delivery, delivery
+	ok.ID, ok.URL = "ok", receiver.URL+"/hook"
+	retry.ID, retry.URL = "retry", receiver.URL+"/broken"
+	last.ID, last.URL, last.Attempts = "last", receiver.URL+"/broken", webhookMaxAttempts-1
+	repo.pending = entities.WebhookDeliveryList{ok, retry, last}
+
+	s := NewWebhookService(repo, nil, receiver.Client())
+	n, err := s.DeliverPending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || calls != 3 {
+		t.Fatalf("delivered %d, receiver called %d times, want 3", n, calls)
+	}
+	if repo.completed["ok"] != http.StatusNoContent {
+		t.Fatalf("ok: completed with %d", repo.completed["ok"])
+	}
+	if repo.retries["retry"] != webhookBackoff || repo.failed["retry"] == "" {
+		t.Fatalf("retry: next attempt in %v, reason %q", repo.retries["retry"], repo.failed["retry"])
+	}
+	if _, ok := repo.failed["last"]; !ok || repo.retries["last"] != 0 {
+		t.Fatalf("last: next attempt in %v, want failed", repo.retries["last"])
+	}
+}
+
+func TestDeliverPendingRejectsLoopback(t *testing.T) {
+	var called bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	repo := newWebhookRepo()
+	repo.pending = entities.WebhookDeliveryList{{ID: "d1", URL: receiver.URL, Payload: []byte(`{}`)}}
+	s := NewWebhookService(repo, nil, nil)
+	if _, err := s.DeliverPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Fatal("default client connected to a loopback address")
+	}
+	if !strings.Contains(repo.failed["d1"], ErrWebhookAddress.Error()) {
+		t.Fatalf("reason %q, want %q", repo.failed["d1"], ErrWebhookAddress)
+	}
+}
+
+func TestPublishCreatesOneDeliveryPerEvent(t *testing.T) {
+	repo := newWebhookRepo(entities.Webhook{ID: "w1"}, entities.Webhook{ID: "w2"})
+	s := NewWebhookService(repo, nil, nil)
+	event, err := NewEvent(entities.EventTenderPublished, "o1", "t1", "", map[string]string{"status": "Published"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(repo.created) != 2 {
+		t.Fatalf("created %d deliveries, want one per webhook", len(repo.created))
+	}
+}
+
+func TestPublishSkipsUnsubmittedBids(t *testing.T) {
+	repo := newWebhookRepo(entities.Webhook{ID: "w1"})
+	s := NewWebhookService(repo, nil, nil)
+	for _, tc := range []struct {
+		typ    entities.EventType
+		status entities.BidStatus
+		sent   bool
+	}{
+		{entities.EventBidCreated, entities.BidStatusCreated, false},
+		{entities.EventBidEdited, entities.BidStatusCreated, false},
+		{entities.EventBidStatusChanged, entities.BidStatusCanceled, false},
+		{entities.EventBidStatusChanged, entities.BidStatusPublished, true},
+		{entities.EventBidRevealed, entities.BidStatusPublished, true},
+	} {
+		repo.created = nil
+		event, err := NewEvent(tc.typ, "o1", "t1", "b1", entities.Bid{ID: "b1", Status: tc.status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+		if sent := len(repo.created) > 0; sent != tc.sent {
+			t.Errorf("%s of %s bid: sent %v, want %v", tc.typ, tc.status, sent, tc.sent)
+		}
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	for _, tc := range []struct {
+		url string
+		err error
+	}{
+		{"ftp://93.184.215.14/hook", ErrInvalidWebhook},
+		{"https:///hook", ErrInvalidWebhook},
+		{"http://127.0.0.1:8080/hook", ErrWebhookAddress},
+		{"http://[::1]/hook", ErrWebhookAddress},
+		{"http://10.1.2.3/hook", ErrWebhookAddress},
+		{"http://192.168.0.10/hook", ErrWebhookAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrWebhookAddress},
+		{"http://100.64.0.1/hook", ErrWebhookAddress},
+		{"http://0.0.0.0/hook", ErrWebhookAddress},
+		{"http://[::ffff:127.0.0.1]/hook", ErrWebhookAddress},
+		{"https://93.184.215.14/hook", nil},
+	} {
+		if err := checkWebhookURL(context.Background(), tc.url); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.url, err, tc.err)
+		}
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"8.8.8.8":     true,
+		"2001:db8::1": true,
+		"127.0.0.2":   false,
+		"172.16.0.1":  false,
+		"fc00::1":     false,
+		"fe80::1":     false,
+		"224.0.0.1":   false,
+	} {
+		if got := publicAddress(net.ParseIP(addr)); got != want {
+			t.Errorf("%s: got %v, want %v", addr, got, want)
+		}
+	}
+}
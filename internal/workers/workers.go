@@ -0,0 +1,31 @@
+// Package workers запускает периодические фоновые задачи сервиса.
+package workers
+
+import (
+	"context"
+	"time"
+	"zadanie-6105/internal/health"
+
+	"go.uber.org/zap"
+)
+
+// Run вызывает fn раз в interval, пока не отменен ctx. После каждого прохода,
+// в том числе неудачного, воркер отмечается в heartbeat: зависшим считается
+// только остановившийся цикл, ошибки прохода видны в логе.
+func Run(ctx context.Context, name string, interval time.Duration, heartbeat *health.Heartbeat,
+	logger *zap.Logger, fn func(ctx context.Context) error) {
+	logger = logger.With(zap.String("worker", name))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("worker run failed", zap.Error(err))
+		}
+		heartbeat.Beat()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS bid;
+DROP TABLE IF EXISTS tender;
+DROP TABLE IF EXISTS organization_responsible;
+DROP TABLE IF EXISTS organization;
+DROP TABLE IF EXISTS employee;
+DROP TYPE IF EXISTS organization_type;
@@ -9,11 +9,17 @@ CREATE TABLE IF NOT EXISTS employee (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-CREATE TYPE organization_type AS ENUM (
-    'IE',
-    'LLC',
-    'JSC'
-);
+-- База могла быть создана прежним init.sql, поэтому тип создается только при отсутствии
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'organization_type') THEN
+        CREATE TYPE organization_type AS ENUM (
+            'IE',
+            'LLC',
+            'JSC'
+        );
+    END IF;
+END$$;
 
 CREATE TABLE IF NOT EXISTS organization (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -55,7 +61,3 @@ CREATE TABLE IF NOT EXISTS bid (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
-
-insert into employee (id, username, first_name, last_name) values ('1c2bb1bd-4d36-4d1d-8b3d-e85a603c0f83', 'ssofiica', 'София', 'Валова');
-insert into organization (id, name, type) values ('90c058c5-e03a-4d4e-9817-9f0d3eb7e1cd','Пиццерия', 'IE');
-insert into organization_responsible (organization_id, user_id) values ('90c058c5-e03a-4d4e-9817-9f0d3eb7e1cd', '1c2bb1bd-4d36-4d1d-8b3d-e85a603c0f83');
\ No newline at end of file
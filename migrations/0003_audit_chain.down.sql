@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS audit_log_chain ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_chain();
+DROP INDEX IF EXISTS audit_log_chain_idx;
+ALTER TABLE audit_log
+    DROP COLUMN IF EXISTS tender_id,
+    DROP COLUMN IF EXISTS seq,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS hash;
+DROP FUNCTION IF EXISTS audit_log_hash(audit_log);
+DROP FUNCTION IF EXISTS audit_log_canonical(audit_log);
+DROP FUNCTION IF EXISTS audit_field(text);
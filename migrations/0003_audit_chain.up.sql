@@ -0,0 +1,96 @@
+-- Записи журнала связываются в цепочку по тендеру: каждая хранит
+-- SHA-256 предыдущей, поэтому изменение любой записи задним числом
+-- ломает все последующие ссылки.
+ALTER TABLE audit_log
+    ADD COLUMN tender_id UUID,
+    ADD COLUMN seq bigint,
+    ADD COLUMN prev_hash text,
+    ADD COLUMN hash text;
+
+-- Каждое поле кодируется как "длина:значение", чтобы содержимое нельзя было
+-- перенести из одного поля в соседнее без изменения хеша.
+CREATE OR REPLACE FUNCTION audit_field(v text) RETURNS text AS $$
+    SELECT octet_length(coalesce(v, ''))::text || ':' || coalesce(v, '')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION audit_log_canonical(e audit_log) RETURNS text AS $$
+    SELECT audit_field(e.seq::text)
+        || audit_field(e.tender_id::text)
+        || audit_field(e.entity_type)
+        || audit_field(e.entity_id::text)
+        || audit_field(e.action)
+        || audit_field(e.actor_id::text)
+        || audit_field(e.organization_id::text)
+        || audit_field(e.diff::text)
+        || audit_field(e.reason)
+        || audit_field(e.request_id)
+        || audit_field(to_char(e.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US'))
+        || audit_field(e.prev_hash)
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION audit_log_hash(e audit_log) RETURNS text AS $$
+    SELECT encode(sha256(convert_to(audit_log_canonical(e), 'UTF8')), 'hex')
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Существующие записи связываются в цепочки в порядке id
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_no_update;
+
+UPDATE audit_log a SET tender_id = CASE
+    WHEN a.entity_type = 'tender' THEN a.entity_id
+    -- предложение могло быть удалено вместе с тендером, тогда цепочка ведется по самой записи
+    ELSE coalesce((SELECT b.tender_id FROM bid b WHERE b.id = a.entity_id), a.entity_id)
+END;
+
+DO $$
+DECLARE
+    r audit_log%ROWTYPE;
+    last_tender UUID;
+    last_seq bigint;
+    last_hash text;
+BEGIN
+    FOR r IN SELECT * FROM audit_log ORDER BY tender_id, id LOOP
+        IF last_tender IS DISTINCT FROM r.tender_id THEN
+            last_tender := r.tender_id;
+            last_seq := 0;
+            last_hash := NULL;
+        END IF;
+        r.seq := last_seq + 1;
+        r.prev_hash := last_hash;
+        r.hash := audit_log_hash(r);
+        UPDATE audit_log SET seq = r.seq, prev_hash = r.prev_hash, hash = r.hash WHERE id = r.id;
+        last_seq := r.seq;
+        last_hash := r.hash;
+    END LOOP;
+END$$;
+
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_no_update;
+
+ALTER TABLE audit_log
+    ALTER COLUMN tender_id SET NOT NULL,
+    ALTER COLUMN seq SET NOT NULL,
+    ALTER COLUMN hash SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS audit_log_chain_idx ON audit_log (tender_id, seq);
+
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS trigger AS $$
+DECLARE
+    last audit_log%ROWTYPE;
+BEGIN
+    -- Вставки в одну цепочку выполняются по очереди до конца транзакции
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log:' || NEW.tender_id::text));
+    SELECT * INTO last FROM audit_log WHERE tender_id = NEW.tender_id ORDER BY seq DESC LIMIT 1;
+    IF FOUND THEN
+        NEW.seq := last.seq + 1;
+        NEW.prev_hash := last.hash;
+    ELSE
+        NEW.seq := 1;
+        NEW.prev_hash := NULL;
+    END IF;
+    NEW.hash := audit_log_hash(NEW);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_chain
+    BEFORE INSERT ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_chain();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bid_signature;
+DROP TABLE IF EXISTS employee_key;
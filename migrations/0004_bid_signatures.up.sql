@@ -0,0 +1,22 @@
+-- Открытые ключи Ed25519 сотрудников. Ключ не удаляется, а отзывается,
+-- чтобы старые подписи оставались проверяемыми.
+CREATE TABLE IF NOT EXISTS employee_key (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    employee_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    public_key text NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    UNIQUE (employee_id, public_key)
+);
+
+-- Подпись автора для каждой версии предложения
+CREATE TABLE IF NOT EXISTS bid_signature (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bid_id UUID NOT NULL REFERENCES bid(id) ON DELETE CASCADE,
+    version integer NOT NULL,
+    key_id UUID NOT NULL REFERENCES employee_key(id),
+    payload text NOT NULL,
+    signature text NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (bid_id, version)
+);
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS tender_reveal_deadline_idx;
+
+UPDATE bid SET status = 'rejected' WHERE status = 'disqualified';
+ALTER TABLE bid DROP CONSTRAINT IF EXISTS bid_status_check;
+ALTER TABLE bid ADD CONSTRAINT bid_status_check
+    CHECK (status IN ('created', 'canceled', 'published', 'approved', 'rejected'));
+
+ALTER TABLE bid
+    DROP COLUMN IF EXISTS commitment,
+    DROP COLUMN IF EXISTS price,
+    DROP COLUMN IF EXISTS salt,
+    DROP COLUMN IF EXISTS revealed_at;
+
+ALTER TABLE tender DROP CONSTRAINT IF EXISTS tender_sealed_deadlines;
+ALTER TABLE tender
+    DROP COLUMN IF EXISTS sealed,
+    DROP COLUMN IF EXISTS bid_deadline,
+    DROP COLUMN IF EXISTS reveal_deadline;
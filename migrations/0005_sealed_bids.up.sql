@@ -0,0 +1,23 @@
+-- Закрытые тендеры: до bid_deadline принимаются только обязательства
+-- sha256(price:salt), до reveal_deadline авторы раскрывают цену и соль.
+ALTER TABLE tender
+    ADD COLUMN IF NOT EXISTS sealed boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS bid_deadline TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS reveal_deadline TIMESTAMP;
+
+ALTER TABLE tender ADD CONSTRAINT tender_sealed_deadlines CHECK (
+    NOT sealed OR (bid_deadline IS NOT NULL AND reveal_deadline > bid_deadline)
+);
+
+ALTER TABLE bid
+    ADD COLUMN IF NOT EXISTS commitment text,
+    ADD COLUMN IF NOT EXISTS price numeric(20, 2),
+    ADD COLUMN IF NOT EXISTS salt text,
+    ADD COLUMN IF NOT EXISTS revealed_at TIMESTAMP;
+
+ALTER TABLE bid DROP CONSTRAINT IF EXISTS bid_status_check;
+ALTER TABLE bid ADD CONSTRAINT bid_status_check
+    CHECK (status IN ('created', 'canceled', 'published', 'approved', 'rejected', 'disqualified'));
+
+-- Для воркера, который дисквалифицирует нераскрытые предложения
+CREATE INDEX IF NOT EXISTS tender_reveal_deadline_idx ON tender (reveal_deadline) WHERE sealed;
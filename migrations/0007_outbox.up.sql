@@ -0,0 +1,17 @@
+-- События пишутся в одной транзакции с изменением и публикуются диспетчером.
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id text NOT NULL UNIQUE,
+    aggregate_type text NOT NULL,
+    aggregate_id UUID NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP,
+    -- попытки исчерпаны, событие больше не публикуется
+    failed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (id) WHERE published_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS outbox_notify ON outbox;
+DROP FUNCTION IF EXISTS outbox_notify();
+DROP INDEX IF EXISTS outbox_tender_idx;
+ALTER TABLE outbox DROP COLUMN IF EXISTS tender_id;
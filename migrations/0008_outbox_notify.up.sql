@@ -0,0 +1,17 @@
+-- tender_id нужен потоку событий тендера для возобновления по Last-Event-ID.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS tender_id UUID;
+UPDATE outbox SET tender_id = (payload->>'tender_id')::uuid WHERE tender_id IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_tender_idx ON outbox (tender_id, id);
+
+-- NOTIFY доставляется после коммита, поэтому слушатели узнают только о
+-- зафиксированных событиях. Полезной нагрузки нет: уведомление лишь будит
+-- слушателя, события он читает из таблицы сам.
+CREATE OR REPLACE FUNCTION outbox_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_notify AFTER INSERT ON outbox
+    FOR EACH STATEMENT EXECUTE FUNCTION outbox_notify();
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS notification (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    event_id text NOT NULL,
+    event_type text NOT NULL,
+    tender_id UUID,
+    bid_id UUID,
+    title text NOT NULL,
+    payload jsonb NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP,
+    -- событие из outbox может прийти повторно
+    UNIQUE (user_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS notification_user_idx ON notification (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS notification_unread_idx ON notification (user_id) WHERE read_at IS NULL;
+
+-- Нет строки — уведомления этого типа включены.
+CREATE TABLE IF NOT EXISTS notification_preference (
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    event_type text NOT NULL,
+    enabled boolean NOT NULL,
+    PRIMARY KEY (user_id, event_type)
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS mail_queue;
+DROP TABLE IF EXISTS mail_settings;
+ALTER TABLE tender DROP COLUMN IF EXISTS bid_deadline_reminded_at;
+ALTER TABLE employee DROP CONSTRAINT IF EXISTS employee_locale_check;
+ALTER TABLE employee DROP COLUMN IF EXISTS locale;
+ALTER TABLE employee DROP COLUMN IF EXISTS email;
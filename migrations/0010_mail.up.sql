@@ -0,0 +1,32 @@
+ALTER TABLE employee ADD COLUMN IF NOT EXISTS email VARCHAR(254);
+ALTER TABLE employee ADD COLUMN IF NOT EXISTS locale text NOT NULL DEFAULT 'ru';
+ALTER TABLE employee ADD CONSTRAINT employee_locale_check CHECK (locale IN ('ru', 'en'));
+
+-- Напоминание о bid_deadline отправляется один раз.
+ALTER TABLE tender ADD COLUMN IF NOT EXISTS bid_deadline_reminded_at TIMESTAMP;
+
+-- Нет строки — письма приходят сразу (instant).
+CREATE TABLE IF NOT EXISTS mail_settings (
+    user_id UUID PRIMARY KEY REFERENCES employee(id) ON DELETE CASCADE,
+    mode text NOT NULL DEFAULT 'instant' CHECK (mode IN ('off', 'instant', 'digest')),
+    last_digest_at TIMESTAMP
+);
+
+-- Письма хранятся уже отрисованными, повтор отправляет то же самое.
+CREATE TABLE IF NOT EXISTS mail_queue (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES employee(id) ON DELETE SET NULL,
+    template text NOT NULL,
+    to_address text NOT NULL,
+    subject text NOT NULL,
+    body_text text NOT NULL,
+    body_html text NOT NULL,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS mail_queue_pending_idx ON mail_queue (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tender_watch;
+DROP TABLE IF EXISTS saved_search;
+ALTER TABLE tender DROP COLUMN IF EXISTS budget;
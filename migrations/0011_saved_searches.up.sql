@@ -0,0 +1,25 @@
+ALTER TABLE tender ADD COLUMN IF NOT EXISTS budget NUMERIC(20, 2) CHECK (budget >= 0);
+
+-- Фильтры повторяют параметры GET /tenders: пустые значения не ограничивают.
+CREATE TABLE IF NOT EXISTS saved_search (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    service_type text[] NOT NULL DEFAULT '{}',
+    query text NOT NULL DEFAULT '',
+    budget_min NUMERIC(20, 2),
+    budget_max NUMERIC(20, 2),
+    channel text NOT NULL DEFAULT 'notification' CHECK (channel IN ('notification', 'email')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS saved_search_user_idx ON saved_search (user_id);
+
+CREATE TABLE IF NOT EXISTS tender_watch (
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    tender_id UUID NOT NULL REFERENCES tender(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, tender_id)
+);
+
+CREATE INDEX IF NOT EXISTS tender_watch_tender_idx ON tender_watch (tender_id);
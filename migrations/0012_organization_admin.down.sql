@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tender_organization_idx;
+ALTER TABLE employee DROP COLUMN IF EXISTS is_admin;
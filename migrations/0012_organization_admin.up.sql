@@ -0,0 +1,4 @@
+-- Администраторы платформы создают организации; назначаются через tenderctl.
+ALTER TABLE employee ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS tender_organization_idx ON tender (organization_id);
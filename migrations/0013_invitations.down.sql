@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS organization_invitation;
+DROP INDEX IF EXISTS organization_responsible_uniq;
@@ -0,0 +1,19 @@
+-- Повторные назначения схлопываются, чтобы можно было запретить дубли.
+DELETE FROM organization_responsible a USING organization_responsible b
+WHERE a.organization_id = b.organization_id AND a.user_id = b.user_id AND a.id > b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS organization_responsible_uniq ON organization_responsible (organization_id, user_id);
+
+-- Хранится только sha256 токена: сам токен знает лишь пригласивший.
+CREATE TABLE IF NOT EXISTS organization_invitation (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organization(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES employee(id) ON DELETE CASCADE,
+    invited_by UUID REFERENCES employee(id) ON DELETE SET NULL,
+    token_hash text UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS organization_invitation_org_idx ON organization_invitation (organization_id);
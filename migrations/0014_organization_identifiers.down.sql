@@ -0,0 +1,6 @@
+ALTER TABLE saved_search DROP COLUMN IF EXISTS inn;
+DROP INDEX IF EXISTS organization_ogrn_uniq;
+DROP INDEX IF EXISTS organization_inn_uniq;
+ALTER TABLE organization DROP COLUMN IF EXISTS kpp;
+ALTER TABLE organization DROP COLUMN IF EXISTS ogrn;
+ALTER TABLE organization DROP COLUMN IF EXISTS inn;
@@ -0,0 +1,10 @@
+-- У ранее созданных организаций реквизитов нет, поэтому столбцы допускают NULL;
+-- контрольные суммы проверяет сервис.
+ALTER TABLE organization ADD COLUMN IF NOT EXISTS inn VARCHAR(12);
+ALTER TABLE organization ADD COLUMN IF NOT EXISTS ogrn VARCHAR(15);
+ALTER TABLE organization ADD COLUMN IF NOT EXISTS kpp VARCHAR(9);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organization_inn_uniq ON organization (inn);
+CREATE UNIQUE INDEX IF NOT EXISTS organization_ogrn_uniq ON organization (ogrn);
+
+ALTER TABLE saved_search ADD COLUMN IF NOT EXISTS inn text NOT NULL DEFAULT '';
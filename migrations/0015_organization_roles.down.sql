@@ -0,0 +1,2 @@
+ALTER TABLE organization_invitation DROP COLUMN IF EXISTS role;
+ALTER TABLE organization_responsible DROP COLUMN IF EXISTS role;
@@ -0,0 +1,6 @@
+-- До появления ролей ответственные могли все, поэтому становятся владельцами.
+ALTER TABLE organization_responsible ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'owner'
+    CHECK (role IN ('owner', 'procurement_manager', 'evaluator', 'viewer', 'bidder'));
+
+ALTER TABLE organization_invitation ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'viewer'
+    CHECK (role IN ('owner', 'procurement_manager', 'evaluator', 'viewer', 'bidder'));
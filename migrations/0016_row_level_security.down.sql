@@ -0,0 +1,18 @@
+DROP POLICY IF EXISTS bid_delete ON bid;
+DROP POLICY IF EXISTS bid_update ON bid;
+DROP POLICY IF EXISTS bid_insert ON bid;
+DROP POLICY IF EXISTS bid_select ON bid;
+ALTER TABLE bid NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE bid DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tender_delete ON tender;
+DROP POLICY IF EXISTS tender_update ON tender;
+DROP POLICY IF EXISTS tender_insert ON tender;
+DROP POLICY IF EXISTS tender_select ON tender;
+ALTER TABLE tender NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tender DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS rls_bid_visible(uuid, text, text, uuid);
+DROP FUNCTION IF EXISTS rls_organization_ids();
+DROP FUNCTION IF EXISTS rls_employee_id();
+DROP FUNCTION IF EXISTS rls_bypass();
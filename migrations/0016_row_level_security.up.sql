@@ -0,0 +1,67 @@
+-- Вторая линия защиты за проверками в сервисах: даже ошибочный запрос
+-- репозитория не вернет чужих неопубликованных тендеров и неподанных
+-- предложений. Переменные app.* выставляет пул соединений перед выдачей
+-- соединения (sessionHook в internal/repositories/visibility.go), а внутри
+-- транзакции их переключает txConn из internal/repositories/tx.go. Фоновые
+-- задачи и tenderctl работают с app.bypass = 'on' (repositories.System); без
+-- переменных строки видны как анониму.
+CREATE OR REPLACE FUNCTION rls_bypass() RETURNS boolean LANGUAGE sql STABLE AS $$
+    SELECT coalesce(current_setting('app.bypass', true), '') = 'on'
+$$;
+
+CREATE OR REPLACE FUNCTION rls_employee_id() RETURNS text LANGUAGE sql STABLE AS $$
+    SELECT coalesce(current_setting('app.employee_id', true), '')
+$$;
+
+-- Организации, тендеры которых сотрудник вправе просматривать.
+CREATE OR REPLACE FUNCTION rls_organization_ids() RETURNS text[] LANGUAGE sql STABLE AS $$
+    SELECT string_to_array(coalesce(current_setting('app.organization_ids', true), ''), ',')
+$$;
+
+CREATE OR REPLACE FUNCTION rls_bid_visible(author_id uuid, author_type text, status text, tender_id uuid)
+RETURNS boolean LANGUAGE sql STABLE AS $$
+    SELECT rls_bypass() OR rls_bid_visible.author_id::text = rls_employee_id()
+        OR (rls_bid_visible.author_type = 'organization' AND EXISTS (
+            SELECT 1 FROM organization_responsible a
+            JOIN organization_responsible v ON v.organization_id = a.organization_id
+            WHERE a.user_id = rls_bid_visible.author_id AND v.user_id::text = rls_employee_id()
+        ))
+        OR (rls_bid_visible.status IN ('published', 'approved', 'rejected', 'disqualified') AND EXISTS (
+            SELECT 1 FROM tender t
+            WHERE t.id = rls_bid_visible.tender_id AND t.organization_id::text = ANY (rls_organization_ids())
+        ))
+$$;
+
+-- Владелец таблиц — тот же пользователь, под которым работает сервис,
+-- поэтому политики применяются принудительно.
+ALTER TABLE tender ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tender FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tender_select ON tender;
+CREATE POLICY tender_select ON tender FOR SELECT
+    USING (rls_bypass() OR status <> 'created' OR organization_id::text = ANY (rls_organization_ids()));
+DROP POLICY IF EXISTS tender_insert ON tender;
+CREATE POLICY tender_insert ON tender FOR INSERT
+    WITH CHECK (rls_bypass() OR organization_id::text = ANY (rls_organization_ids()));
+DROP POLICY IF EXISTS tender_update ON tender;
+CREATE POLICY tender_update ON tender FOR UPDATE
+    USING (rls_bypass() OR organization_id::text = ANY (rls_organization_ids()));
+DROP POLICY IF EXISTS tender_delete ON tender;
+CREATE POLICY tender_delete ON tender FOR DELETE
+    USING (rls_bypass() OR organization_id::text = ANY (rls_organization_ids()));
+
+ALTER TABLE bid ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bid FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bid_select ON bid;
+CREATE POLICY bid_select ON bid FOR SELECT
+    USING (rls_bid_visible(author_id, author_type, status, tender_id));
+DROP POLICY IF EXISTS bid_insert ON bid;
+CREATE POLICY bid_insert ON bid FOR INSERT
+    WITH CHECK (rls_bypass() OR author_id::text = rls_employee_id());
+DROP POLICY IF EXISTS bid_update ON bid;
+CREATE POLICY bid_update ON bid FOR UPDATE
+    USING (rls_bid_visible(author_id, author_type, status, tender_id));
+DROP POLICY IF EXISTS bid_delete ON bid;
+CREATE POLICY bid_delete ON bid FOR DELETE
+    USING (rls_bypass() OR author_id::text = rls_employee_id());
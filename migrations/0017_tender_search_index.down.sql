@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tender_search_idx;
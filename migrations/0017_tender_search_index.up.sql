@@ -0,0 +1,6 @@
+-- Триграммный индекс для поиска по словам в названии и описании (параметр
+-- query списков тендеров). Перестраивается командой tenderctl search reindex.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS tender_search_idx ON tender
+    USING gin (lower(name || ' ' || coalesce(description, '')) gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS webhook_delivery_event_uniq;
+ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS event_id;
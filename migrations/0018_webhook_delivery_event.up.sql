@@ -0,0 +1,7 @@
+-- Доставка события вебхуку создается не больше одного раза: диспетчер
+-- outbox может повторить публикацию после частичного сбоя. У повторных
+-- доставок (Redeliver) и записей до этой миграции event_id пуст.
+ALTER TABLE webhook_delivery ADD COLUMN IF NOT EXISTS event_id text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_delivery_event_uniq ON webhook_delivery (webhook_id, event_id)
+    WHERE event_id IS NOT NULL;
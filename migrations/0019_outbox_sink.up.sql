@@ -0,0 +1,8 @@
+-- Получатели, уже принявшие событие. После частичного сбоя диспетчер
+-- повторяет событие только для остальных.
+CREATE TABLE IF NOT EXISTS outbox_sink (
+    outbox_id BIGINT NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    sink text NOT NULL,
+    published_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (outbox_id, sink)
+);
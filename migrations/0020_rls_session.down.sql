@@ -0,0 +1,16 @@
+CREATE OR REPLACE FUNCTION rls_organization_ids() RETURNS text[] LANGUAGE sql STABLE AS $$
+    SELECT string_to_array(coalesce(current_setting('app.organization_ids', true), ''), ',')
+$$;
+
+DROP POLICY IF EXISTS tender_select ON tender;
+CREATE POLICY tender_select ON tender FOR SELECT
+    USING (rls_bypass() OR status <> 'created' OR organization_id::text = ANY (rls_organization_ids()));
+DROP POLICY IF EXISTS tender_insert ON tender;
+CREATE POLICY tender_insert ON tender FOR INSERT
+    WITH CHECK (rls_bypass() OR organization_id::text = ANY (rls_organization_ids()));
+DROP POLICY IF EXISTS tender_update ON tender;
+CREATE POLICY tender_update ON tender FOR UPDATE
+    USING (rls_bypass() OR organization_id::text = ANY (rls_organization_ids()));
+DROP POLICY IF EXISTS tender_delete ON tender;
+CREATE POLICY tender_delete ON tender FOR DELETE
+    USING (rls_bypass() OR organization_id::text = ANY (rls_organization_ids()));
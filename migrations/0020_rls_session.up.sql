@@ -0,0 +1,24 @@
+-- Пул больше не читает organization_responsible при выдаче соединения:
+-- он выставляет только app.bypass, app.employee_id и app.view_roles, а
+-- список организаций сотрудника считают политики. Миграции работают с
+-- app.bypass = 'on' внутри своей транзакции.
+CREATE OR REPLACE FUNCTION rls_organization_ids() RETURNS text[] LANGUAGE sql STABLE AS $$
+    SELECT coalesce(array_agg(organization_id::text), '{}')
+    FROM organization_responsible
+    WHERE user_id::text = rls_employee_id()
+        AND role = ANY (string_to_array(coalesce(current_setting('app.view_roles', true), ''), ','))
+$$;
+
+-- (SELECT ...) вычисляется один раз на запрос, а не на каждую строку.
+DROP POLICY IF EXISTS tender_select ON tender;
+CREATE POLICY tender_select ON tender FOR SELECT
+    USING (rls_bypass() OR status <> 'created' OR organization_id::text = ANY ((SELECT rls_organization_ids())));
+DROP POLICY IF EXISTS tender_insert ON tender;
+CREATE POLICY tender_insert ON tender FOR INSERT
+    WITH CHECK (rls_bypass() OR organization_id::text = ANY ((SELECT rls_organization_ids())));
+DROP POLICY IF EXISTS tender_update ON tender;
+CREATE POLICY tender_update ON tender FOR UPDATE
+    USING (rls_bypass() OR organization_id::text = ANY ((SELECT rls_organization_ids())));
+DROP POLICY IF EXISTS tender_delete ON tender;
+CREATE POLICY tender_delete ON tender FOR DELETE
+    USING (rls_bypass() OR organization_id::text = ANY ((SELECT rls_organization_ids())));
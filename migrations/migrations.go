@@ -0,0 +1,265 @@
+// Package migrations содержит пронумерованные миграции схемы и применяет их.
+//
+// Файлы миграций называются NNNN_name.up.sql и NNNN_name.down.sql,
+// примененные версии хранятся в таблице schema_migrations.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockID — ключ advisory lock, чтобы несколько экземпляров сервиса
+// не применяли миграции одновременно.
+const lockID = 6105
+
+// bypassRLS снимает политики RLS (миграция 0016) до конца транзакции:
+// миграции и seed меняют строки tender и bid любых организаций.
+const bypassRLS = `select set_config('app.bypass', 'on', true)`
+
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+func New(db *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func load() ([]Migration, error) {
+	names, err := fs.Glob(files, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	var res []Migration
+	for _, name := range names {
+		base := strings.TrimSuffix(name, ".up.sql")
+		num, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrations: неверное имя файла %s", name)
+		}
+		version, err := strconv.Atoi(num)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: неверная версия в %s: %w", name, err)
+		}
+		up, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		down, err := files.ReadFile(base + ".down.sql")
+		if err != nil {
+			return nil, fmt.Errorf("migrations: нет down-миграции для %s: %w", name, err)
+		}
+		res = append(res, Migration{Version: version, Name: title, up: string(up), down: string(down)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// Latest возвращает последнюю известную сервису версию схемы.
+func Latest() int {
+	migrations, err := load()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			st.AppliedAt = &at
+		}
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// Version возвращает текущую версию схемы в базе, 0 — если миграций не было.
+// Схему не меняет, поэтому подходит для проверок готовности.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var exists bool
+	err := m.db.QueryRow(ctx, `select to_regclass('schema_migrations') is not null`).Scan(&exists)
+	if err != nil || !exists {
+		return 0, err
+	}
+	var version int
+	err = m.db.QueryRow(ctx, `select coalesce(max(version), 0) from schema_migrations`).Scan(&version)
+	return version, err
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down откатывает одну последнюю примененную миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	target := 0
+	for _, mig := range m.migrations {
+		if mig.Version < current {
+			target = mig.Version
+		}
+	}
+	return m.To(ctx, target)
+}
+
+// To применяет или откатывает миграции до версии version включительно.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("migrations: неизвестная версия %d", version)
+	}
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, `select pg_advisory_lock($1)`, lockID); err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), `select pg_advisory_unlock($1)`, lockID)
+
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok || mig.Version > version {
+			continue
+		}
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, bypassRLS); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, mig.up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `insert into schema_migrations(version, name) values ($1, $2)`, mig.Version, mig.Name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migrations: up %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok || mig.Version <= version {
+			continue
+		}
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, bypassRLS); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, mig.down); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `delete from schema_migrations where version=$1`, mig.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migrations: down %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return nil
+}
+
+// Seed заполняет базу начальными данными. Повторный запуск ничего не меняет.
+func (m *Migrator) Seed(ctx context.Context) error {
+	seed, err := files.ReadFile("seed.sql")
+	if err != nil {
+		return err
+	}
+	return pgx.BeginFunc(ctx, m.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, bypassRLS); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, string(seed))
+		return err
+	})
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.Exec(ctx, `
+		create table if not exists schema_migrations (
+			version integer primary key,
+			name text not null,
+			applied_at timestamptz not null default now()
+		)`)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.Query(ctx, `select version, applied_at from schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[int]time.Time{}
+	for rows.Next() {
+		var (
+			version int
+			at      time.Time
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		res[version] = at
+	}
+	return res, rows.Err()
+}
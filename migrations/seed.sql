@@ -0,0 +1,13 @@
+insert into employee (id, username, first_name, last_name) values ('1c2bb1bd-4d36-4d1d-8b3d-e85a603c0f83', 'ssofiica', 'София', 'Валова')
+on conflict do nothing;
+insert into employee (id, username, first_name, is_admin) values ('6f1e0c1a-2b7d-4c55-9a0e-3d8f4b2c7e91', 'admin', 'Администратор', true)
+on conflict do nothing;
+insert into organization (id, name, type, inn, ogrn) values ('90c058c5-e03a-4d4e-9817-9f0d3eb7e1cd','Пиццерия', 'IE',
+'771234567859', '318774600123452')
+on conflict do nothing;
+insert into organization_responsible (organization_id, user_id)
+select '90c058c5-e03a-4d4e-9817-9f0d3eb7e1cd', '1c2bb1bd-4d36-4d1d-8b3d-e85a603c0f83'
+where not exists (
+    select 1 from organization_responsible
+    where organization_id='90c058c5-e03a-4d4e-9817-9f0d3eb7e1cd' and user_id='1c2bb1bd-4d36-4d1d-8b3d-e85a603c0f83'
+);
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type (
+	AuditEntry  = entities.AuditEntry
+	AuditEntity = entities.AuditEntity
+	ChainReport = entities.ChainReport
+	ChainHead   = entities.ChainHead
+)
+
+// AuditEntries возвращает журнал изменений тендера или предложения по
+// порядку записи.
+func (c *Client) AuditEntries(ctx context.Context, entity AuditEntity, id string) ([]AuditEntry, error) {
+	var res []AuditEntry
+	query := url.Values{"entity": {string(entity)}, "id": {id}}
+	err := c.do(ctx, http.MethodGet, "/audit", query, nil, &res)
+	return res, err
+}
+
+// VerifyChain просит сервис пересчитать цепочку хешей журнала тендера.
+func (c *Client) VerifyChain(ctx context.Context, tenderID string) (ChainReport, error) {
+	var res ChainReport
+	err := c.do(ctx, http.MethodGet, "/audit/verify", url.Values{"tenderId": {tenderID}}, nil, &res)
+	return res, err
+}
+
+// ChainHead возвращает подписанную голову цепочки журнала тендера.
+func (c *Client) ChainHead(ctx context.Context, tenderID string) (ChainHead, error) {
+	var res ChainHead
+	err := c.do(ctx, http.MethodGet, "/audit/head", url.Values{"tenderId": {tenderID}}, nil, &res)
+	return res, err
+}
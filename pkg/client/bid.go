@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type BidListParams struct {
+	Limit  int
+	Offset int
+}
+
+func (c *Client) CreateBid(ctx context.Context, bid Bid) (Bid, error) {
+	var res Bid
+	err := c.do(ctx, http.MethodPost, "/bids/new", nil, bid, &res)
+	return res, err
+}
+
+func (c *Client) GetMyBids(ctx context.Context, params BidListParams) (BidList, error) {
+	var res BidList
+	err := c.do(ctx, http.MethodGet, "/bids/my", pageValues(params.Limit, params.Offset), nil, &res)
+	return res, err
+}
+
+func (c *Client) MyBids(params BidListParams) *Iterator[Bid] {
+	return newIterator(params.Limit, params.Offset, func(ctx context.Context, limit, offset int) ([]Bid, error) {
+		return c.GetMyBids(ctx, BidListParams{Limit: limit, Offset: offset})
+	})
+}
+
+func (c *Client) GetBidsForTender(ctx context.Context, tenderID string, params BidListParams) (BidList, error) {
+	var res BidList
+	err := c.do(ctx, http.MethodGet, "/bids/"+url.PathEscape(tenderID)+"/my", pageValues(params.Limit, params.Offset), nil, &res)
+	return res, err
+}
+
+func (c *Client) BidsForTender(tenderID string, params BidListParams) *Iterator[Bid] {
+	return newIterator(params.Limit, params.Offset, func(ctx context.Context, limit, offset int) ([]Bid, error) {
+		return c.GetBidsForTender(ctx, tenderID, BidListParams{Limit: limit, Offset: offset})
+	})
+}
+
+func (c *Client) GetBidStatus(ctx context.Context, id string) (BidStatus, error) {
+	var res BidStatus
+	err := c.do(ctx, http.MethodGet, "/bids/"+url.PathEscape(id)+"/status", nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) ChangeBidStatus(ctx context.Context, id string, status BidStatus) (Bid, error) {
+	var res Bid
+	q := url.Values{"status": {string(status)}}
+	err := c.do(ctx, http.MethodPut, "/bids/"+url.PathEscape(id)+"/status", q, nil, &res)
+	return res, err
+}
+
+// SubmitBidDecision выносит решение по предложению от имени ответственного
+// за организацию тендера.
+func (c *Client) SubmitBidDecision(ctx context.Context, id string, decision BidStatus) (Bid, error) {
+	var res Bid
+	q := url.Values{"decision": {string(decision)}}
+	err := c.do(ctx, http.MethodPut, "/bids/"+url.PathEscape(id)+"/submit_decision", q, nil, &res)
+	return res, err
+}
+
+func (c *Client) EditBid(ctx context.Context, id string, bid Bid) (Bid, error) {
+	var res Bid
+	err := c.do(ctx, http.MethodPatch, "/bids/"+url.PathEscape(id)+"/edit", nil, bid, &res)
+	return res, err
+}
+
+// RevealBid раскрывает цену предложения закрытого тендера после bid_deadline.
+// Обязательство при подаче считается через BidCommitment(price, salt).
+func (c *Client) RevealBid(ctx context.Context, id string, price string, salt string) (Bid, error) {
+	var res Bid
+	body := map[string]string{"price": price, "salt": salt}
+	err := c.do(ctx, http.MethodPut, "/bids/"+url.PathEscape(id)+"/reveal", nil, body, &res)
+	return res, err
+}
+
+// BidCommitment — обязательство для закрытого тендера, price в виде "1500.00".
+func BidCommitment(price string, salt string) string {
+	return entities.BidCommitment(price, salt)
+}
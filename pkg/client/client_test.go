@@ -0,0 +1,345 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"zadanie-6105/internal/delivery"
+	"zadanie-6105/internal/delivery/operation"
+	"zadanie-6105/internal/repositories/entities"
+	"zadanie-6105/internal/services"
+	"zadanie-6105/pkg/client"
+
+	"go.uber.org/zap"
+)
+
+const (
+	responsible = "ssofiica"
+	stranger    = "stranger"
+)
+
+type viewers struct{}
+
+func (viewers) Viewer(ctx context.Context, username string) (entities.Viewer, error) {
+	return entities.Viewer{ID: username}, nil
+}
+
+// tenders — сервис тендеров с семью опубликованными тендерами; менять их
+// может только responsible.
+type tenders struct {
+	services.Tender
+	list    entities.TenderList
+	changes atomic.Int32
+}
+
+func newTenders() *tenders {
+	t := &tenders{}
+	for i := 0; i < 7; i++ {
+		t.list = append(t.list, entities.Tender{
+			ID:     fmt.Sprintf("t%d", i),
+			Name:   fmt.Sprintf("Тендер %d", i),
+			Status: entities.TenderStatusPublished,
+		})
+	}
+	return t
+}
+
+func (t *tenders) GetTenderList(ctx context.Context, params operation.TenderListParams, username string) (entities.TenderList, error) {
+	from := min(int(params.Offset), len(t.list))
+	to := min(from+int(params.Limit), len(t.list))
+	return t.list[from:to], nil
+}
+
+func (t *tenders) CreateTender(ctx context.Context, tender entities.Tender) (entities.Tender, error) {
+	if tender.CreatorUsername != responsible {
+		return entities.Tender{}, services.ErrNotResponsible
+	}
+	tender.ID, tender.Status, tender.Version = "new", entities.TenderStatusCreated, 1
+	return tender, nil
+}
+
+func (t *tenders) ChangeTenderStatus(ctx context.Context, status entities.TenderStatus, id string, username string) (entities.Tender, error) {
+	t.changes.Add(1)
+	switch username {
+	case responsible:
+		return entities.Tender{ID: id, Status: status, Version: 2}, nil
+	case stranger:
+		return entities.Tender{}, services.ErrNoAccess
+	}
+	return entities.Tender{}, services.ErrUserNotFound
+}
+
+type bids struct {
+	services.Bid
+	decisions atomic.Int32
+}
+
+func (b *bids) CreateBid(ctx context.Context, bid entities.Bid) (entities.Bid, error) {
+	bid.ID, bid.Status, bid.Version = "b1", entities.BidStatusCreated, 1
+	return bid, nil
+}
+
+func (b *bids) SubmitBid(ctx context.Context, decision entities.BidStatus, id string, username string) (entities.Bid, error) {
+	b.decisions.Add(1)
+	if username != responsible {
+		return entities.Bid{}, services.ErrPermissionDenied
+	}
+	return entities.Bid{ID: id, Status: decision}, nil
+}
+
+// audit — журнал тендера t1 из двух записей; читать его может только
+// responsible.
+type audit struct {
+	services.Audit
+}
+
+func (audit) GetEntries(ctx context.Context, entity entities.AuditEntity, id string, username string) (entities.AuditList, error) {
+	if username != responsible {
+		return nil, services.ErrNoAccess
+	}
+	return entities.AuditList{
+		{ID: 1, TenderID: "t1", Seq: 1, EntityType: entity, EntityID: id, Action: entities.AuditActionCreate, Hash: "h1"},
+		{ID: 2, TenderID: "t1", Seq: 2, EntityType: entity, EntityID: id, Action: entities.AuditActionEdit, PrevHash: "h1", Hash: "h2"},
+	}, nil
+}
+
+func (audit) VerifyChain(ctx context.Context, tenderId string, username string) (entities.ChainReport, error) {
+	if username != responsible {
+		return entities.ChainReport{}, services.ErrNoAccess
+	}
+	return entities.ChainReport{TenderID: tenderId, Entries: 2, Valid: true, Head: "h2"}, nil
+}
+
+func (audit) ChainHead(ctx context.Context, tenderId string, username string) (entities.ChainHead, error) {
+	if username != responsible {
+		return entities.ChainHead{}, services.ErrNoAccess
+	}
+	return entities.ChainHead{TenderID: tenderId, Seq: 2, Hash: "h2", Algorithm: "ed25519"}, nil
+}
+
+// failing отвечает 503 на первые n запросов и считает все запросы.
+type failing struct {
+	next  http.Handler
+	n     int32
+	calls atomic.Int32
+}
+
+func (f *failing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.calls.Add(1) <= f.n {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func newServer(t *testing.T, tenderService *tenders, bidService *bids) *httptest.Server {
+	t.Helper()
+	logger := zap.NewNop()
+	router := delivery.NewRouter(delivery.Handlers{
+		Tender: delivery.NewTenderHandler(tenderService, logger),
+		Bid:    delivery.NewBidHandler(bidService, logger),
+		Audit:  delivery.NewAuditHandler(audit{}, logger),
+	}, viewers{}, logger)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPing(t *testing.T) {
+	srv := newServer(t, newTenders(), &bids{})
+	if err := client.New(srv.URL).Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTendersIterator(t *testing.T) {
+	service := newTenders()
+	srv := newServer(t, service, &bids{})
+	c := client.New(srv.URL, client.WithUsername(responsible))
+
+	var got []string
+	it := c.Tenders(client.TenderListParams{Limit: 3})
+	for it.Next(context.Background()) {
+		got = append(got, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(service.list) {
+		t.Fatalf("got %d tenders, want %d: %v", len(got), len(service.list), got)
+	}
+	for i, id := range got {
+		if id != service.list[i].ID {
+			t.Fatalf("tender %d = %s, want %s", i, id, service.list[i].ID)
+		}
+	}
+}
+
+func TestCreateTenderAndChangeStatus(t *testing.T) {
+	srv := newServer(t, newTenders(), &bids{})
+	c := client.New(srv.URL, client.WithUsername(responsible))
+	ctx := context.Background()
+
+	created, err := c.CreateTender(ctx, client.Tender{Name: "Доставка", CreatorUsername: responsible})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "new" || created.Status != entities.TenderStatusCreated {
+		t.Fatalf("unexpected tender %+v", created)
+	}
+	changed, err := c.ChangeTenderStatus(ctx, created.ID, entities.TenderStatusPublished)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Status != entities.TenderStatusPublished || changed.Version != 2 {
+		t.Fatalf("unexpected tender %+v", changed)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := newServer(t, newTenders(), &bids{})
+	ctx := context.Background()
+
+	_, err := client.New(srv.URL, client.WithUsername(stranger)).
+		ChangeTenderStatus(ctx, "t1", entities.TenderStatusClosed)
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("stranger: got %v, want ErrForbidden", err)
+	}
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.Reason != services.ErrNoAccess.Error() {
+		t.Fatalf("stranger: reason %v", err)
+	}
+
+	_, err = client.New(srv.URL, client.WithUsername("nobody")).
+		ChangeTenderStatus(ctx, "t1", entities.TenderStatusClosed)
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("unknown user: got %v, want ErrUnauthorized", err)
+	}
+
+	_, err = client.New(srv.URL).ChangeTenderStatus(ctx, "t1", entities.TenderStatusClosed)
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("no username: got %v, want ErrUnauthorized", err)
+	}
+
+	_, err = client.New(srv.URL, client.WithUsername(responsible)).
+		ChangeTenderStatus(ctx, "t1", "unknown")
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("bad status: got %v, want ErrBadRequest", err)
+	}
+}
+
+func TestBidDecision(t *testing.T) {
+	bidService := &bids{}
+	srv := newServer(t, newTenders(), bidService)
+	ctx := context.Background()
+
+	bid, err := client.New(srv.URL).CreateBid(ctx, client.Bid{Name: "Предложение", TenderID: "t1", AuthorType: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bid.ID != "b1" || bid.Status != entities.BidStatusCreated {
+		t.Fatalf("unexpected bid %+v", bid)
+	}
+	decided, err := client.New(srv.URL, client.WithUsername(responsible)).
+		SubmitBidDecision(ctx, bid.ID, entities.BidStatusApproved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decided.Status != entities.BidStatusApproved {
+		t.Fatalf("unexpected bid %+v", decided)
+	}
+	_, err = client.New(srv.URL, client.WithUsername(stranger)).
+		SubmitBidDecision(ctx, bid.ID, entities.BidStatusApproved)
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("got %v, want ErrForbidden", err)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	srv := newServer(t, newTenders(), &bids{})
+	ctx := context.Background()
+	c := client.New(srv.URL, client.WithUsername(responsible))
+
+	entries, err := c.AuditEntries(ctx, entities.AuditEntityTender, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].PrevHash != entries[0].Hash || entries[1].EntityID != "t1" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	report, err := c.VerifyChain(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid || report.Entries != 2 || report.Head != "h2" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	head, err := c.ChainHead(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.TenderID != "t1" || head.Seq != 2 || head.Hash != report.Head {
+		t.Fatalf("unexpected head %+v", head)
+	}
+
+	_, err = client.New(srv.URL, client.WithUsername(stranger)).VerifyChain(ctx, "t1")
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Fatalf("stranger: got %v, want ErrForbidden", err)
+	}
+	_, err = c.AuditEntries(ctx, "unknown", "t1")
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("bad entity: got %v, want ErrBadRequest", err)
+	}
+}
+
+func TestRetriesOnlyReads(t *testing.T) {
+	service, bidService := newTenders(), &bids{}
+	srv := newServer(t, service, bidService)
+	ctx := context.Background()
+
+	flaky := &failing{next: srv.Config.Handler, n: 2}
+	retrying := httptest.NewServer(flaky)
+	defer retrying.Close()
+	c := client.New(retrying.URL, client.WithUsername(responsible), client.WithRetries(3, time.Millisecond))
+	if _, err := c.GetTenderList(ctx, client.TenderListParams{}); err != nil {
+		t.Fatalf("GET should succeed after retries: %v", err)
+	}
+	if got := flaky.calls.Load(); got != 3 {
+		t.Fatalf("GET sent %d times, want 3", got)
+	}
+
+	flaky = &failing{next: srv.Config.Handler, n: 1}
+	once := httptest.NewServer(flaky)
+	defer once.Close()
+	c = client.New(once.URL, client.WithUsername(responsible), client.WithRetries(3, time.Millisecond))
+	_, err := c.SubmitBidDecision(ctx, "b1", entities.BidStatusApproved)
+	if !errors.Is(err, client.ErrServer) {
+		t.Fatalf("got %v, want ErrServer", err)
+	}
+	if got := flaky.calls.Load(); got != 1 {
+		t.Fatalf("PUT sent %d times, want 1", got)
+	}
+	if got := bidService.decisions.Load(); got != 0 {
+		t.Fatalf("decision applied %d times, want 0", got)
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	srv := newServer(t, newTenders(), &bids{})
+	flaky := &failing{next: srv.Config.Handler, n: 100}
+	retrying := httptest.NewServer(flaky)
+	defer retrying.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c := client.New(retrying.URL, client.WithRetries(100, 20*time.Millisecond))
+	_, err := c.GetTenderList(ctx, client.TenderListParams{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type (
+	Employee         = entities.Employee
+	Invitation       = entities.Invitation
+	OrganizationRole = entities.OrganizationRole
+)
+
+// CreateEmployee регистрирует нового сотрудника; username клиента не нужен.
+func (c *Client) CreateEmployee(ctx context.Context, employee Employee) (Employee, error) {
+	var res Employee
+	err := c.do(ctx, http.MethodPost, "/employees", nil, employee, &res)
+	return res, err
+}
+
+// EditEmployee меняет только непустые поля employee.
+func (c *Client) EditEmployee(ctx context.Context, id string, employee Employee) (Employee, error) {
+	var res Employee
+	err := c.do(ctx, http.MethodPatch, "/employees/"+url.PathEscape(id), nil, employee, &res)
+	return res, err
+}
+
+func (c *Client) GetResponsibles(ctx context.Context, organizationId string) ([]Employee, error) {
+	var res []Employee
+	err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(organizationId)+"/responsibles", nil, nil, &res)
+	return res, err
+}
+
+// InviteResponsible возвращает приглашение с одноразовым токеном; токен
+// больше нигде не отдается. Пустая role — viewer.
+func (c *Client) InviteResponsible(ctx context.Context, organizationId string, username string,
+	role OrganizationRole) (Invitation, error) {
+	var res Invitation
+	body := struct {
+		Username string           `json:"username"`
+		Role     OrganizationRole `json:"role,omitempty"`
+	}{username, role}
+	err := c.do(ctx, http.MethodPost, "/organizations/"+url.PathEscape(organizationId)+"/invitations", nil, body, &res)
+	return res, err
+}
+
+func (c *Client) AcceptInvitation(ctx context.Context, token string) (Organization, error) {
+	var res Organization
+	body := struct {
+		Token string `json:"token"`
+	}{token}
+	err := c.do(ctx, http.MethodPost, "/invitations/accept", nil, body, &res)
+	return res, err
+}
+
+func (c *Client) RemoveResponsible(ctx context.Context, organizationId string, employeeId string) error {
+	return c.do(ctx, http.MethodDelete, "/organizations/"+url.PathEscape(organizationId)+"/responsibles/"+url.PathEscape(employeeId), nil, nil, nil)
+}
+
+func (c *Client) SetRole(ctx context.Context, organizationId string, employeeId string, role OrganizationRole) error {
+	body := struct {
+		Role OrganizationRole `json:"role"`
+	}{role}
+	return c.do(ctx, http.MethodPut, "/organizations/"+url.PathEscape(organizationId)+"/responsibles/"+url.PathEscape(employeeId), nil, body, nil)
+}
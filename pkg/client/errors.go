@@ -0,0 +1,61 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrBadRequest   = errors.New("неверный формат запроса или его параметры")
+	ErrUnauthorized = errors.New("пользователь не существует или некорректен")
+	ErrForbidden    = errors.New("недостаточно прав для совершения действия")
+	ErrNotFound     = errors.New("не найдено")
+	// ErrGone — поток событий не может дослать пропущенное, см. StreamEvents.
+	ErrGone   = errors.New("пропущенные события недоступны")
+	ErrServer = errors.New("ошибка сервера")
+)
+
+// Error — ответ сервиса со статусом 4xx/5xx. Проверяется через errors.Is
+// по одной из ошибок Err*.
+type Error struct {
+	StatusCode int
+	Reason     string
+}
+
+func newError(code int, body []byte) *Error {
+	e := &Error{StatusCode: code}
+	var resp struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		e.Reason = resp.Reason
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("api: %d %s", e.StatusCode, e.Reason)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrGone:
+		return e.StatusCode == http.StatusGone
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package client
+
+import "context"
+
+const defaultPageSize = 5
+
+// Iterator постранично обходит список, запрашивая следующую страницу
+// по мере необходимости:
+//
+//	it := c.Tenders(client.TenderListParams{})
+//	for it.Next(ctx) {
+//		t := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] struct {
+	fetch  func(ctx context.Context, limit, offset int) ([]T, error)
+	limit  int
+	offset int
+	page   []T
+	pos    int
+	cur    T
+	done   bool
+	err    error
+}
+
+func newIterator[T any](limit, offset int, fetch func(ctx context.Context, limit, offset int) ([]T, error)) *Iterator[T] {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	return &Iterator[T]{fetch: fetch, limit: limit, offset: offset}
+}
+
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.page) {
+		if it.done {
+			return false
+		}
+		page, err := it.fetch(ctx, it.limit, it.offset)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.pos = page, 0
+		it.offset += len(page)
+		if len(page) < it.limit {
+			it.done = true
+		}
+		if len(page) == 0 {
+			return false
+		}
+	}
+	it.cur = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *Iterator[T]) Value() T {
+	return it.cur
+}
+
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+)
+
+// RegisterKey регистрирует открытый ключ текущего пользователя для подписи предложений.
+func (c *Client) RegisterKey(ctx context.Context, publicKey ed25519.PublicKey) (EmployeeKey, error) {
+	var res EmployeeKey
+	body := map[string]string{"public_key": base64.StdEncoding.EncodeToString(publicKey)}
+	err := c.do(ctx, http.MethodPost, "/employees/keys", nil, body, &res)
+	return res, err
+}
+
+func (c *Client) GetKeys(ctx context.Context) ([]EmployeeKey, error) {
+	var res []EmployeeKey
+	err := c.do(ctx, http.MethodGet, "/employees/keys", nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) RevokeKey(ctx context.Context, id string) (EmployeeKey, error) {
+	var res EmployeeKey
+	err := c.do(ctx, http.MethodDelete, "/employees/keys/"+url.PathEscape(id), nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) GetBidSignatures(ctx context.Context, id string) ([]BidSignature, error) {
+	var res []BidSignature
+	err := c.do(ctx, http.MethodGet, "/bids/"+url.PathEscape(id)+"/signatures", nil, nil, &res)
+	return res, err
+}
+
+// SignBid подписывает версию предложения bid ключом keyID. bid должен
+// содержать итоговые значения всех полей и номер версии, которую
+// создаст запрос: 1 для CreateBid, текущая+1 для EditBid.
+func SignBid(bid Bid, keyID string, key ed25519.PrivateKey) (Bid, error) {
+	payload, err := bid.SigningPayload()
+	if err != nil {
+		return Bid{}, err
+	}
+	bid.Signature = &BidSignature{
+		KeyID:     keyID,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}
+	return bid, nil
+}
+
+// VerifyBidSignature проверяет сохраненную подпись версии предложения.
+func VerifyBidSignature(sig BidSignature) bool {
+	publicKey, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(publicKey, []byte(sig.Payload), signature)
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type (
+	Notification            = entities.Notification
+	NotificationPreferences = entities.NotificationPreferences
+	MailSettings            = entities.MailSettings
+)
+
+// GetNotifications возвращает уведомления пользователя, новые первыми;
+// unread — только непрочитанные.
+func (c *Client) GetNotifications(ctx context.Context, unread bool, limit int, offset int) ([]Notification, error) {
+	query := pageValues(limit, offset)
+	if unread {
+		query.Set("unread", "true")
+	}
+	var res []Notification
+	err := c.do(ctx, http.MethodGet, "/notifications", query, nil, &res)
+	return res, err
+}
+
+func (c *Client) MarkNotificationRead(ctx context.Context, id string) (Notification, error) {
+	var res Notification
+	err := c.do(ctx, http.MethodPut, "/notifications/"+url.PathEscape(id)+"/read", nil, nil, &res)
+	return res, err
+}
+
+// MarkAllNotificationsRead возвращает число отмеченных уведомлений.
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) (int, error) {
+	var res struct {
+		Marked int `json:"marked"`
+	}
+	err := c.do(ctx, http.MethodPut, "/notifications/read_all", nil, nil, &res)
+	return res.Marked, err
+}
+
+func (c *Client) GetNotificationPreferences(ctx context.Context) (NotificationPreferences, error) {
+	var res NotificationPreferences
+	err := c.do(ctx, http.MethodGet, "/notifications/preferences", nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) SetNotificationPreferences(ctx context.Context, prefs NotificationPreferences) (NotificationPreferences, error) {
+	var res NotificationPreferences
+	err := c.do(ctx, http.MethodPut, "/notifications/preferences", nil, prefs, &res)
+	return res, err
+}
+
+func (c *Client) GetMailSettings(ctx context.Context) (MailSettings, error) {
+	var res MailSettings
+	err := c.do(ctx, http.MethodGet, "/notifications/email", nil, nil, &res)
+	return res, err
+}
+
+// SetMailSettings заменяет настройки писем целиком; пустой Email отключает их.
+func (c *Client) SetMailSettings(ctx context.Context, settings MailSettings) (MailSettings, error) {
+	var res MailSettings
+	err := c.do(ctx, http.MethodPut, "/notifications/email", nil, settings, &res)
+	return res, err
+}
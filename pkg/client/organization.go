@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type (
+	Organization        = entities.Organization
+	OrganizationType    = entities.OrganizationType
+	OrganizationSummary = entities.OrganizationSummary
+)
+
+func (c *Client) GetOrganizations(ctx context.Context, limit int, offset int) ([]Organization, error) {
+	var res []Organization
+	err := c.do(ctx, http.MethodGet, "/organizations", pageValues(limit, offset), nil, &res)
+	return res, err
+}
+
+func (c *Client) GetOrganization(ctx context.Context, id string) (Organization, error) {
+	var res Organization
+	err := c.do(ctx, http.MethodGet, "/organizations/"+url.PathEscape(id), nil, nil, &res)
+	return res, err
+}
+
+// CreateOrganization доступно только администраторам платформы.
+func (c *Client) CreateOrganization(ctx context.Context, org Organization) (Organization, error) {
+	var res Organization
+	err := c.do(ctx, http.MethodPost, "/organizations", nil, org, &res)
+	return res, err
+}
+
+// EditOrganization меняет только непустые поля org.
+func (c *Client) EditOrganization(ctx context.Context, id string, org Organization) (Organization, error) {
+	var res Organization
+	err := c.do(ctx, http.MethodPatch, "/organizations/"+url.PathEscape(id), nil, org, &res)
+	return res, err
+}
+
+func (c *Client) DeleteOrganization(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/organizations/"+url.PathEscape(id), nil, nil, nil)
+}
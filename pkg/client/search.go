@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type (
+	SavedSearch  = entities.SavedSearch
+	TenderFilter = entities.TenderFilter
+)
+
+func (c *Client) GetSearches(ctx context.Context) ([]SavedSearch, error) {
+	var res []SavedSearch
+	err := c.do(ctx, http.MethodGet, "/searches", nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) CreateSearch(ctx context.Context, search SavedSearch) (SavedSearch, error) {
+	var res SavedSearch
+	err := c.do(ctx, http.MethodPost, "/searches", nil, search, &res)
+	return res, err
+}
+
+func (c *Client) DeleteSearch(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/searches/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// WatchTender подписывает на все изменения тендера, watch == false — отписывает.
+func (c *Client) WatchTender(ctx context.Context, tenderID string, watch bool) error {
+	method := http.MethodPut
+	if !watch {
+		method = http.MethodDelete
+	}
+	return c.do(ctx, method, "/tenders/"+url.PathEscape(tenderID)+"/watch", nil, nil, nil)
+}
+
+func (c *Client) GetWatchedTenders(ctx context.Context, limit int, offset int) (TenderList, error) {
+	var res TenderList
+	err := c.do(ctx, http.MethodGet, "/tenders/watched", pageValues(limit, offset), nil, &res)
+	return res, err
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+type StreamEvent = entities.StreamEvent
+
+// StreamEvents читает поток изменений тендера и вызывает fn на каждое событие.
+// Разорванное соединение восстанавливается с Last-Event-ID, начиная после
+// lastEventId (0 — только новые события). Возвращает ошибку fn, ошибку API
+// (4xx; ErrGone — пропущено больше, чем сервис досылает) или ctx.Err().
+func (c *Client) StreamEvents(ctx context.Context, tenderId string, lastEventId int64, fn func(StreamEvent) error) error {
+	query := url.Values{}
+	query.Set("tenderId", tenderId)
+	if c.username != "" {
+		query.Set("username", c.username)
+	}
+	u := c.baseURL + "/api/events/stream?" + query.Encode()
+	// таймаут клиента оборвал бы долгий поток
+	hc := *c.httpClient
+	hc.Timeout = 0
+	backoff := c.backoff
+	for {
+		err := c.readStream(ctx, &hc, u, &lastEventId, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (c *Client) readStream(ctx context.Context, hc *http.Client, u string, lastEventId *int64, fn func(StreamEvent) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastEventId > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(*lastEventId, 10))
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(resp.Body)
+		return newError(resp.StatusCode, data)
+	}
+	var (
+		id   int64
+		data strings.Builder
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var event StreamEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return err
+			}
+			event.ID = id
+			data.Reset()
+			if err := fn(event); err != nil {
+				return err
+			}
+			// событие, зафиксированное позже, может прийти с меньшим id
+			*lastEventId = max(*lastEventId, id)
+		case strings.HasPrefix(line, "id: "):
+			id, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+		case strings.HasPrefix(line, "data: "):
+			data.WriteString(strings.TrimPrefix(line, "data: "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type TenderListParams struct {
+	Limit       int
+	Offset      int
+	ServiceType []TenderType
+	// Query — слова, каждое из которых должно быть в названии или описании
+	Query     string
+	BudgetMin string
+	BudgetMax string
+	INN       string
+}
+
+func (p TenderListParams) values() url.Values {
+	q := pageValues(p.Limit, p.Offset)
+	for _, s := range p.ServiceType {
+		q.Add("service_type", string(s))
+	}
+	if p.Query != "" {
+		q.Set("query", p.Query)
+	}
+	if p.BudgetMin != "" {
+		q.Set("budget_min", p.BudgetMin)
+	}
+	if p.BudgetMax != "" {
+		q.Set("budget_max", p.BudgetMax)
+	}
+	if p.INN != "" {
+		q.Set("inn", p.INN)
+	}
+	return q
+}
+
+func (c *Client) GetTenderList(ctx context.Context, params TenderListParams) (TenderList, error) {
+	var res TenderList
+	err := c.do(ctx, http.MethodGet, "/tenders", params.values(), nil, &res)
+	return res, err
+}
+
+// Tenders обходит все тендеры, начиная с params.Offset, страницами по params.Limit.
+func (c *Client) Tenders(params TenderListParams) *Iterator[Tender] {
+	return newIterator(params.Limit, params.Offset, func(ctx context.Context, limit, offset int) ([]Tender, error) {
+		p := params
+		p.Limit, p.Offset = limit, offset
+		return c.GetTenderList(ctx, p)
+	})
+}
+
+func (c *Client) CreateTender(ctx context.Context, tender Tender) (Tender, error) {
+	var res Tender
+	err := c.do(ctx, http.MethodPost, "/tenders/new", nil, tender, &res)
+	return res, err
+}
+
+func (c *Client) GetMyTenders(ctx context.Context, params TenderListParams) (TenderList, error) {
+	var res TenderList
+	err := c.do(ctx, http.MethodGet, "/tenders/my", params.values(), nil, &res)
+	return res, err
+}
+
+func (c *Client) MyTenders(params TenderListParams) *Iterator[Tender] {
+	return newIterator(params.Limit, params.Offset, func(ctx context.Context, limit, offset int) ([]Tender, error) {
+		p := params
+		p.Limit, p.Offset = limit, offset
+		return c.GetMyTenders(ctx, p)
+	})
+}
+
+func (c *Client) GetTenderStatus(ctx context.Context, id string) (TenderStatus, error) {
+	var res TenderStatus
+	err := c.do(ctx, http.MethodGet, "/tenders/"+url.PathEscape(id)+"/status", nil, nil, &res)
+	return res, err
+}
+
+func (c *Client) ChangeTenderStatus(ctx context.Context, id string, status TenderStatus) (Tender, error) {
+	var res Tender
+	q := url.Values{"status": {string(status)}}
+	err := c.do(ctx, http.MethodPut, "/tenders/"+url.PathEscape(id)+"/status", q, nil, &res)
+	return res, err
+}
+
+// EditTender обновляет непустые поля name, description и service_type.
+func (c *Client) EditTender(ctx context.Context, id string, tender Tender) (Tender, error) {
+	var res Tender
+	err := c.do(ctx, http.MethodPatch, "/tenders/"+url.PathEscape(id)+"/edit", nil, tender, &res)
+	return res, err
+}
+
+func pageValues(limit, offset int) url.Values {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+	return q
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"zadanie-6105/internal/repositories/entities"
+)
+
+// ErrBadSignature — подпись входящего вебхука не сошлась или устарела.
+var ErrBadSignature = errors.New("client: неверная подпись вебхука")
+
+func (c *Client) CreateWebhook(ctx context.Context, hook Webhook) (Webhook, error) {
+	var res Webhook
+	err := c.do(ctx, http.MethodPost, "/webhooks", nil, hook, &res)
+	return res, err
+}
+
+func (c *Client) GetWebhooks(ctx context.Context, organizationID string) ([]Webhook, error) {
+	var res []Webhook
+	err := c.do(ctx, http.MethodGet, "/webhooks", url.Values{"organizationId": {organizationID}}, nil, &res)
+	return res, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/webhooks/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func (c *Client) GetDeliveries(ctx context.Context, webhookID string, limit int, offset int) ([]Delivery, error) {
+	var res []Delivery
+	err := c.do(ctx, http.MethodGet, "/webhooks/"+url.PathEscape(webhookID)+"/deliveries", pageValues(limit, offset), nil, &res)
+	return res, err
+}
+
+func (c *Client) Redeliver(ctx context.Context, deliveryID string) (Delivery, error) {
+	var res Delivery
+	err := c.do(ctx, http.MethodPost, "/webhooks/deliveries/"+url.PathEscape(deliveryID)+"/redeliver", nil, nil, &res)
+	return res, err
+}
+
+// ParseWebhook проверяет подпись входящего вебхука и возвращает событие.
+// Запросы старше tolerance отклоняются, чтобы их нельзя было переиграть.
+func ParseWebhook(r *http.Request, secret string, tolerance time.Duration) (Event, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Event{}, err
+	}
+	timestamp := r.Header.Get("X-Webhook-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Event{}, ErrBadSignature
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > tolerance || age < -tolerance {
+		return Event{}, ErrBadSignature
+	}
+	expected := entities.WebhookSignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Webhook-Signature"))) {
+		return Event{}, ErrBadSignature
+	}
+	var event Event
+	err = json.Unmarshal(body, &event)
+	return event, err
+}